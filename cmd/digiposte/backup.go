@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/holyhope/digiposte-go-sdk/v1/backup"
+)
+
+// backupSummary is the result of the backup command.
+type backupSummary struct {
+	Path      string `json:"path"`
+	Format    string `json:"format"`
+	Folders   int    `json:"folders"`
+	Documents int    `json:"documents"`
+	Shares    int    `json:"shares"`
+	// Archived is the number of documents whose content is in the archive.
+	Archived int `json:"archived"`
+}
+
+func (a *app) backup(ctx context.Context, args []string) error {
+	var (
+		noTrash bool
+		workers int
+		since   string
+	)
+
+	args, err := a.parseFlags("backup", "[-no-trash] [-workers n] [-since previous] <file.zip|file.tar.gz>", args, 1, 1,
+		func(flags *flag.FlagSet) {
+			flags.BoolVar(&noTrash, "no-trash", false, "leave the trash out of the backup")
+			flags.IntVar(&workers, "workers", 0, "number of documents downloaded at the same time")
+			flags.StringVar(&since, "since", "",
+				"previous backup archive or manifest.json: only archive the documents changed since then")
+		})
+	if err != nil {
+		return err
+	}
+
+	format, err := backup.FormatFromName(args[0])
+	if err != nil {
+		return &usageError{Command: "backup", Message: err.Error()}
+	}
+
+	options := []backup.Option{}
+
+	if noTrash {
+		options = append(options, backup.WithoutTrash())
+	}
+
+	if workers > 0 {
+		options = append(options, backup.WithWorkers(workers))
+	}
+
+	if since != "" {
+		previous, err := readManifest(since)
+		if err != nil {
+			return err
+		}
+
+		options = append(options, backup.Incremental(previous))
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(args[0], os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+
+	manifest, err := backup.Write(ctx, client, file, format, options...)
+	if err == nil {
+		err = file.Close()
+	} else {
+		_ = file.Close()
+	}
+
+	if err != nil {
+		_ = os.Remove(args[0])
+
+		return fmt.Errorf("backup: %w", err)
+	}
+
+	summary := &backupSummary{
+		Path:      args[0],
+		Format:    format.String(),
+		Folders:   len(manifest.Folders),
+		Documents: len(manifest.Documents),
+		Shares:    len(manifest.Shares),
+		Archived:  0,
+	}
+
+	for _, document := range manifest.Documents {
+		if manifest.Archived(document) {
+			summary.Archived++
+		}
+	}
+
+	return a.print(summary, func(writer io.Writer) {
+		fmt.Fprintf(writer, "Backed up %d documents (%d archived), %d folders and %d shares to %s\n",
+			summary.Documents, summary.Archived, summary.Folders, summary.Shares, summary.Path)
+	})
+}
+
+// readManifest reads a manifest file, or the manifest of a backup archive.
+func readManifest(name string) (*backup.Manifest, error) {
+	if !strings.HasSuffix(strings.ToLower(name), ".json") {
+		archive, err := backup.OpenReader(name)
+		if err != nil {
+			return nil, fmt.Errorf("open previous backup: %w", err)
+		}
+
+		defer archive.Close()
+
+		return archive.Manifest, nil
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("open previous manifest: %w", err)
+	}
+
+	defer file.Close()
+
+	manifest, err := backup.ReadManifest(file)
+	if err != nil {
+		return nil, fmt.Errorf("read previous manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// restoreSummary is the result of the restore command.
+type restoreSummary struct {
+	DryRun         bool `json:"dry_run"`
+	CreatedFolders int  `json:"created_folders"`
+	Uploaded       int  `json:"uploaded"`
+	Skipped        int  `json:"skipped"`
+}
+
+func (a *app) restoreBackup(ctx context.Context, args []string) error {
+	var (
+		dryRun       bool
+		skipExisting bool
+		into         string
+		mappingFile  string
+	)
+
+	args, err := a.parseFlags("restore",
+		"[-dry-run] [-skip-existing] [-into path] [-mapping file.json] <file.zip|file.tar.gz> [previous archive]...",
+		args, 1, -1,
+		func(flags *flag.FlagSet) {
+			flags.BoolVar(&dryRun, "dry-run", false, "show what would be restored, without changing the account")
+			flags.BoolVar(&skipExisting, "skip-existing", false, "keep the documents already present with the same name")
+			flags.StringVar(&into, "into", "", "folder to restore into, instead of the root")
+			flags.StringVar(&mappingFile, "mapping", "", "file to write the mapping from the old IDs to the new IDs to")
+		})
+	if err != nil {
+		return err
+	}
+
+	options := []backup.RestoreOption{}
+
+	if dryRun {
+		options = append(options, backup.DryRun())
+	}
+
+	if skipExisting {
+		options = append(options, backup.SkipExisting())
+	}
+
+	if into != "" {
+		options = append(options, backup.IntoFolder(into))
+	}
+
+	archive, err := backup.OpenReader(args[0])
+	if err != nil {
+		return fmt.Errorf("open backup: %w", err)
+	}
+
+	defer archive.Close()
+
+	// The archives of the previous backups hold the documents left unchanged by an incremental backup.
+	previous := make([]*backup.Reader, 0, len(args)-1)
+
+	for _, name := range args[1:] {
+		reader, err := backup.OpenReader(name)
+		if err != nil {
+			return fmt.Errorf("open previous backup: %w", err)
+		}
+
+		defer reader.Close()
+
+		previous = append(previous, reader)
+	}
+
+	if len(previous) > 0 {
+		options = append(options, backup.WithPreviousArchives(previous...))
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, restoreErr := backup.Restore(ctx, client, archive, options...)
+	if result == nil {
+		return fmt.Errorf("restore: %w", restoreErr)
+	}
+
+	if mappingFile != "" {
+		if err := writeMapping(mappingFile, &result.Mapping); err != nil {
+			return err
+		}
+	}
+
+	summary := &restoreSummary{
+		DryRun:         dryRun,
+		CreatedFolders: len(result.CreatedFolders),
+		Uploaded:       len(result.Uploaded),
+		Skipped:        len(result.Skipped),
+	}
+
+	if err := a.print(summary, func(writer io.Writer) {
+		verb := "Restored"
+		if summary.DryRun {
+			verb = "Would restore"
+		}
+
+		fmt.Fprintf(writer, "%s %d documents and %d folders, skipped %d documents\n",
+			verb, summary.Uploaded, summary.CreatedFolders, summary.Skipped)
+	}); err != nil {
+		return err
+	}
+
+	if restoreErr != nil {
+		return fmt.Errorf("restore: %w", restoreErr)
+	}
+
+	return nil
+}
+
+func writeMapping(name string, mapping *backup.Mapping) error {
+	data, err := json.MarshalIndent(mapping, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode mapping: %w", err)
+	}
+
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		return fmt.Errorf("write mapping: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+func TestDigiposte(t *testing.T) {
+	t.Parallel()
+
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "Digiposte Command Suite")
+}
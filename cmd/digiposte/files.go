@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+func (a *app) login(ctx context.Context, args []string) error {
+	if _, err := a.parseFlags("login", "", args, 0, 0, nil); err != nil {
+		return err
+	}
+
+	// Ignore the saved session to force a new login.
+	client, err := a.newClient(ctx, new(digiposte.Session))
+	if err != nil {
+		return err
+	}
+
+	profile, err := client.GetProfile(ctx, digiposte.ProfileModeNoSpaceConsumption)
+	if err != nil {
+		return fmt.Errorf("get profile: %w", err)
+	}
+
+	return a.print(&profile.UserInfo, func(writer io.Writer) {
+		fmt.Fprintf(writer, "Logged in as %s %s <%s>\n", profile.FirstName, profile.LastName, profile.Email)
+	})
+}
+
+func (a *app) logout(ctx context.Context, args []string) error {
+	if _, err := a.parseFlags("logout", "", args, 0, 0, nil); err != nil {
+		return err
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Logout(ctx); err != nil {
+		return fmt.Errorf("logout: %w", err)
+	}
+
+	return nil
+}
+
+func (a *app) profile(ctx context.Context, args []string) error {
+	if _, err := a.parseFlags("profile", "", args, 0, 0, nil); err != nil {
+		return err
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	profile, err := client.GetProfile(ctx, digiposte.ProfileModeDefault)
+	if err != nil {
+		return fmt.Errorf("get profile: %w", err)
+	}
+
+	return a.print(profile, func(writer io.Writer) {
+		fmt.Fprintf(writer, "Name:\t%s %s\n", profile.FirstName, profile.LastName)
+		fmt.Fprintf(writer, "Email:\t%s\n", profile.Email)
+		fmt.Fprintf(writer, "Offer:\t%s\n", profile.Offer.CommercialName)
+		fmt.Fprintf(writer, "Space:\t%s / %s\n", formatSize(profile.SpaceUsed), formatSize(profile.Offer.MaxSafeSize))
+	})
+}
+
+func (a *app) list(ctx context.Context, args []string) error {
+	args, err := a.parseFlags("ls", "[path]", args, 0, 1, nil)
+	if err != nil {
+		return err
+	}
+
+	path := "/"
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	found, err := client.StatPath(ctx, path)
+	if err != nil {
+		return fmt.Errorf("resolve: %w", err)
+	}
+
+	result := &listing{
+		Folders:   []*digiposte.Folder{},
+		Documents: []*digiposte.Document{},
+	}
+
+	if found.Document != nil {
+		result.Documents = append(result.Documents, found.Document)
+	} else {
+		result.Folders = found.Folder.Folders
+
+		result.Documents, err = client.SearchDocumentsIter(ctx, found.Folder.InternalID).All()
+		if err != nil {
+			return fmt.Errorf("search documents: %w", err)
+		}
+	}
+
+	return a.print(result, result.write)
+}
+
+// download is the result of the get command.
+type download struct {
+	Document *digiposte.Document `json:"document"`
+	Path     string              `json:"path"`
+}
+
+func (a *app) get(ctx context.Context, args []string) error {
+	args, err := a.parseFlags("get", "<path> [destination]", args, 1, 2, nil)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	document, err := client.NewResolver().ResolveDocument(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("resolve: %w", err)
+	}
+
+	destination := filepath.Base(document.Name)
+	if len(args) > 1 {
+		destination = args[1]
+	}
+
+	if info, err := os.Stat(destination); err == nil && info.IsDir() {
+		destination = filepath.Join(destination, filepath.Base(document.Name))
+	}
+
+	stream, err := client.DocumentStream(ctx, document.InternalID)
+	if err != nil {
+		return fmt.Errorf("get document content: %w", err)
+	}
+
+	defer stream.Close()
+
+	if destination == "-" {
+		if _, err := io.Copy(a.stdout, stream); err != nil {
+			return fmt.Errorf("write content: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := writeFile(destination, stream); err != nil {
+		return err
+	}
+
+	return a.print(&download{Document: document, Path: destination}, func(writer io.Writer) {
+		fmt.Fprintf(writer, "Downloaded %s to %s (%s)\n", document.Name, destination, formatSize(document.Size))
+	})
+}
+
+func writeFile(path string, content io.Reader) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+
+	if _, err := io.Copy(file, content); err != nil {
+		_ = file.Close()
+
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close file: %w", err)
+	}
+
+	return nil
+}
+
+func (a *app) put(ctx context.Context, args []string) error {
+	var (
+		name   string
+		health bool
+	)
+
+	args, err := a.parseFlags("put", "[-name name] [-health] <file> [folder]", args, 1, 2, func(flags *flag.FlagSet) {
+		flags.StringVar(&name, "name", "", "name of the document (default: the name of the file)")
+		flags.BoolVar(&health, "health", false, "upload the document as a health document")
+	})
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		name = filepath.Base(args[0])
+	}
+
+	docType := digiposte.DocumentTypeBasic
+	if health {
+		docType = digiposte.DocumentTypeHealth
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	folderID := digiposte.RootFolderID
+
+	if len(args) > 1 {
+		folder, err := client.NewResolver().ResolveFolder(ctx, args[1])
+		if err != nil {
+			return fmt.Errorf("resolve: %w", err)
+		}
+
+		folderID = folder.InternalID
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+
+	defer file.Close()
+
+	document, err := client.CreateDocument(ctx, folderID, name, file, docType)
+	if err != nil {
+		return fmt.Errorf("create document: %w", err)
+	}
+
+	return a.print(document, func(writer io.Writer) {
+		fmt.Fprintf(writer, "Uploaded %s (%s) as %s\n", document.Name, formatSize(document.Size), document.InternalID)
+	})
+}
+
+// treeResult is the result of the commands acting on several documents and folders.
+type treeResult struct {
+	DocumentIDs []digiposte.DocumentID `json:"document_ids"`
+	FolderIDs   []digiposte.FolderID   `json:"folder_ids"`
+}
+
+func (r *treeResult) writer(action string) func(writer io.Writer) {
+	return func(writer io.Writer) {
+		fmt.Fprintf(writer, "%s %d documents and %d folders\n", action, len(r.DocumentIDs), len(r.FolderIDs))
+	}
+}
+
+func (a *app) move(ctx context.Context, args []string) error {
+	args, err := a.parseFlags("mv", "<path>... <folder>", args, 2, -1, nil)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	resolver := client.NewResolver()
+
+	destination, err := resolver.ResolveFolder(ctx, args[len(args)-1])
+	if err != nil {
+		return fmt.Errorf("resolve: %w", err)
+	}
+
+	documentIDs, folderIDs, err := resolveAll(ctx, resolver, args[:len(args)-1])
+	if err != nil {
+		return err
+	}
+
+	if err := client.Move(ctx, destination.InternalID, documentIDs, folderIDs); err != nil {
+		return fmt.Errorf("move: %w", err)
+	}
+
+	result := &treeResult{DocumentIDs: documentIDs, FolderIDs: folderIDs}
+
+	return a.print(result, result.writer("Moved"))
+}
+
+func (a *app) remove(ctx context.Context, args []string) error {
+	args, err := a.parseFlags("rm", "<path>...", args, 1, -1, nil)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	documentIDs, folderIDs, err := resolveAll(ctx, client.NewResolver(), args)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Trash(ctx, documentIDs, folderIDs); err != nil {
+		return fmt.Errorf("trash: %w", err)
+	}
+
+	result := &treeResult{DocumentIDs: documentIDs, FolderIDs: folderIDs}
+
+	return a.print(result, result.writer("Trashed"))
+}
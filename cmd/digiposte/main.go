@@ -0,0 +1,254 @@
+// Command digiposte manages a Digiposte safe from the command line.
+//
+// Usage:
+//
+//	digiposte [flags] <command> [arguments]
+//
+// The first command, usually "digiposte login", logs in with chrome using the
+// DIGIPOSTE_USERNAME, DIGIPOSTE_PASSWORD and DIGIPOSTE_OTP_SECRET environment variables.
+// The session is then saved and reused by the next invocations.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/holyhope/digiposte-go-sdk/login"
+	"github.com/holyhope/digiposte-go-sdk/settings"
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	err := newApp(os.Stdout, os.Stderr).run(ctx, os.Args[1:])
+
+	var usageErr *usageError
+
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(os.Stderr, "digiposte: %v\n", err)
+
+		return 2
+	default:
+		fmt.Fprintf(os.Stderr, "digiposte: %v\n", err)
+
+		return 1
+	}
+}
+
+// app holds the global flags shared by all the commands.
+type app struct {
+	stdout io.Writer
+	stderr io.Writer
+
+	apiURL      string
+	documentURL string
+	sessionFile string
+	json        bool
+
+	// loginMethod replaces the chrome login method when not nil.
+	loginMethod login.Method
+
+	clientOptions []digiposte.ClientOption
+}
+
+func newApp(stdout, stderr io.Writer) *app {
+	return &app{
+		stdout:      stdout,
+		stderr:      stderr,
+		apiURL:      envOrDefault("DIGIPOSTE_API", settings.DefaultAPIURL),
+		documentURL: envOrDefault("DIGIPOSTE_URL", settings.DefaultDocumentURL),
+		sessionFile: defaultSessionFile(),
+		json:        false,
+		loginMethod: nil,
+		clientOptions: []digiposte.ClientOption{
+			digiposte.WithRateLimit(digiposte.DefaultRateLimit(), digiposte.DefaultRateLimit()),
+			digiposte.WithRetryPolicy(digiposte.DefaultRetryPolicy()),
+		},
+	}
+}
+
+func envOrDefault(name, defaultValue string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+
+	return defaultValue
+}
+
+// command is a sub-command of the digiposte binary.
+type command struct {
+	name        string
+	summary     string
+	run         func(ctx context.Context, args []string) error
+	subcommands []*command
+}
+
+func (a *app) commands() []*command {
+	return []*command{
+		{name: "login", summary: "log in and save the session", run: a.login, subcommands: nil},
+		{name: "logout", summary: "log out and remove the saved session", run: a.logout, subcommands: nil},
+		{name: "ls", summary: "list a folder", run: a.list, subcommands: nil},
+		{name: "get", summary: "download a document", run: a.get, subcommands: nil},
+		{name: "put", summary: "upload a document", run: a.put, subcommands: nil},
+		{name: "mv", summary: "move documents and folders to a folder", run: a.move, subcommands: nil},
+		{name: "rm", summary: "move documents and folders to the trash", run: a.remove, subcommands: nil},
+		{name: "purge", summary: "delete permanently items of the trash", run: a.purge, subcommands: nil},
+		{name: "tag", summary: "list the tags, or tag a document", run: a.tag, subcommands: nil},
+		{name: "share", summary: "manage the shares", run: nil, subcommands: []*command{
+			{name: "create", summary: "create a share", run: a.createShare, subcommands: nil},
+			{name: "list", summary: "list the shares", run: a.listShares, subcommands: nil},
+			{name: "rm", summary: "delete shares", run: a.removeShares, subcommands: nil},
+		}},
+		{name: "profile", summary: "show the profile of the user", run: a.profile, subcommands: nil},
+		{name: "backup", summary: "write the whole account to an archive", run: a.backup, subcommands: nil},
+		{name: "restore", summary: "recreate an archive in the account", run: a.restoreBackup, subcommands: nil},
+		{name: "watch", summary: "report the changes of the unread inbox", run: a.watch, subcommands: nil},
+		{name: "trash", summary: "manage the trash", run: nil, subcommands: []*command{
+			{name: "ls", summary: "list the trash", run: a.listTrash, subcommands: nil},
+			{name: "restore", summary: "restore items of the trash", run: a.restore, subcommands: nil},
+			{name: "empty", summary: "delete permanently all the items of the trash", run: a.emptyTrash, subcommands: nil},
+		}},
+	}
+}
+
+// run parses the global flags and runs the command.
+func (a *app) run(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("digiposte", flag.ContinueOnError)
+	flags.SetOutput(a.stderr)
+	flags.StringVar(&a.apiURL, "api-url", a.apiURL, "URL of the Digiposte API (env DIGIPOSTE_API)")
+	flags.StringVar(&a.documentURL, "document-url", a.documentURL, "URL of the Digiposte documents (env DIGIPOSTE_URL)")
+	flags.StringVar(&a.sessionFile, "session", a.sessionFile, "file the session is saved to (env DIGIPOSTE_SESSION)")
+	flags.BoolVar(&a.json, "json", a.json, "print the results as JSON")
+
+	commands := a.commands()
+
+	flags.Usage = func() {
+		fmt.Fprintf(a.stderr, "Usage: digiposte [flags] <command> [arguments]\n\nCommands:\n")
+		printCommands(a.stderr, "", commands)
+		fmt.Fprintf(a.stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return parseError("", err)
+	}
+
+	return a.dispatch(ctx, nil, commands, flags.Args())
+}
+
+func printCommands(writer io.Writer, prefix string, commands []*command) {
+	for _, cmd := range commands {
+		if cmd.run != nil {
+			fmt.Fprintf(writer, "  %-16s %s\n", prefix+cmd.name, cmd.summary)
+		}
+
+		printCommands(writer, prefix+cmd.name+" ", cmd.subcommands)
+	}
+}
+
+func (a *app) dispatch(ctx context.Context, parents []string, commands []*command, args []string) error {
+	names := make([]string, 0, len(commands))
+
+	for _, cmd := range commands {
+		names = append(names, cmd.name)
+	}
+
+	if len(args) == 0 {
+		return &usageError{
+			Command: strings.Join(parents, " "),
+			Message: "missing command, expected one of " + strings.Join(names, ", "),
+		}
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+
+		if cmd.run != nil {
+			return cmd.run(ctx, args[1:])
+		}
+
+		return a.dispatch(ctx, append(parents, cmd.name), cmd.subcommands, args[1:])
+	}
+
+	return &usageError{
+		Command: strings.Join(parents, " "),
+		Message: fmt.Sprintf("unknown command %q, expected one of %s", args[0], strings.Join(names, ", ")),
+	}
+}
+
+// usageError is returned when a command is called with invalid arguments.
+type usageError struct {
+	Command string
+	Message string
+}
+
+func (e *usageError) Error() string {
+	if e.Command == "" {
+		return e.Message
+	}
+
+	return e.Command + ": " + e.Message
+}
+
+// parseError turns an error of flag.Parse into a usage error.
+func parseError(command string, err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return flag.ErrHelp
+	}
+
+	return &usageError{Command: command, Message: err.Error()}
+}
+
+// parseFlags parses the flags of a command and checks the number of remaining arguments.
+// The global -json flag is also accepted after the command name.
+// A negative maxArgs means no limit.
+func (a *app) parseFlags(
+	name, synopsis string,
+	args []string,
+	minArgs, maxArgs int,
+	setup func(flags *flag.FlagSet),
+) ([]string, error) {
+	usage := strings.TrimSpace(name + " " + synopsis)
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(a.stderr)
+	flags.BoolVar(&a.json, "json", a.json, "print the results as JSON")
+	flags.Usage = func() {
+		fmt.Fprintf(a.stderr, "Usage: digiposte %s\n\nFlags:\n", usage)
+		flags.PrintDefaults()
+	}
+
+	if setup != nil {
+		setup(flags)
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, parseError(name, err)
+	}
+
+	if flags.NArg() < minArgs || (maxArgs >= 0 && flags.NArg() > maxArgs) {
+		return nil, &usageError{
+			Command: name,
+			Message: "usage: digiposte " + usage,
+		}
+	}
+
+	return flags.Args(), nil
+}
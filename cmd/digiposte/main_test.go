@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/gstruct"
+	"golang.org/x/oauth2"
+
+	"github.com/holyhope/digiposte-go-sdk/login"
+	"github.com/holyhope/digiposte-go-sdk/v1"
+	"github.com/holyhope/digiposte-go-sdk/v1/digipostetest"
+)
+
+var (
+	errLoginDisabled = errors.New("login disabled")
+	errWrite         = errors.New("write failed")
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWrite
+}
+
+var _ = ginkgo.Describe("digiposte", func() {
+	var (
+		server      *digipostetest.Server
+		loginMethod login.Method
+		dir         string
+		stdout      *bytes.Buffer
+	)
+
+	runWith := func(ctx context.Context, output io.Writer, args ...string) error {
+		cli := newApp(output, ginkgo.GinkgoWriter)
+		cli.apiURL = server.APIURL()
+		cli.documentURL = server.DocumentURL()
+		cli.sessionFile = filepath.Join(dir, "session.json")
+		cli.loginMethod = loginMethod
+		cli.clientOptions = nil
+
+		return cli.run(ctx, args)
+	}
+
+	run := func(ctx context.Context, args ...string) error {
+		stdout.Reset()
+
+		return runWith(ctx, stdout, args...)
+	}
+
+	ginkgo.BeforeEach(func() {
+		server = digipostetest.NewServer()
+		ginkgo.DeferCleanup(server.Close)
+
+		loginMethod = server.LoginMethod()
+		dir = ginkgo.GinkgoT().TempDir()
+		stdout = new(bytes.Buffer)
+	})
+
+	ginkgo.Describe("login", func() {
+		ginkgo.It("Should save the session", func(ctx ginkgo.SpecContext) {
+			gomega.Expect(run(ctx, "login")).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).To(gomega.ContainSubstring(digipostetest.Email))
+			gomega.Expect(filepath.Join(dir, "session.json")).To(gomega.BeAnExistingFile())
+		})
+
+		ginkgo.It("Should reuse the saved session", func(ctx ginkgo.SpecContext) {
+			gomega.Expect(run(ctx, "login")).To(gomega.Succeed())
+
+			loginMethod = login.MethodFunc(func(context.Context, *login.Credentials) (*oauth2.Token, []*http.Cookie, error) {
+				return nil, nil, errLoginDisabled
+			})
+
+			gomega.Expect(run(ctx, "profile")).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).To(gomega.ContainSubstring(digipostetest.Email))
+		})
+
+		ginkgo.It("Should remove the session on logout", func(ctx ginkgo.SpecContext) {
+			gomega.Expect(run(ctx, "login")).To(gomega.Succeed())
+			gomega.Expect(run(ctx, "logout")).To(gomega.Succeed())
+			gomega.Expect(filepath.Join(dir, "session.json")).ToNot(gomega.BeAnExistingFile())
+		})
+	})
+
+	ginkgo.Context("When the safe has documents", func() {
+		var (
+			folder   *digiposte.Folder
+			document *digiposte.Document
+		)
+
+		ginkgo.BeforeEach(func() {
+			folder = server.AddFolder(digiposte.RootFolderID, "Impôts")
+			server.AddFolder(folder.InternalID, "2024")
+			document = server.AddDocument(digiposte.RootFolderID, "avis.txt", []byte("the content"), digiposte.LocationSafe)
+		})
+
+		ginkgo.It("Should list a folder", func(ctx ginkgo.SpecContext) {
+			gomega.Expect(run(ctx, "ls")).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).To(gomega.ContainSubstring("Impôts/"))
+			gomega.Expect(stdout.String()).To(gomega.ContainSubstring("avis.txt"))
+
+			gomega.Expect(run(ctx, "ls", "/Impôts")).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).To(gomega.ContainSubstring("2024/"))
+		})
+
+		ginkgo.It("Should print JSON", func(ctx ginkgo.SpecContext) {
+			gomega.Expect(run(ctx, "ls", "-json", "/")).To(gomega.Succeed())
+
+			result := new(listing)
+			gomega.Expect(json.Unmarshal(stdout.Bytes(), result)).To(gomega.Succeed())
+			gomega.Expect(result.Folders).To(gomega.HaveLen(1))
+			gomega.Expect(result.Documents).To(gomega.ConsistOf(gstruct.PointTo(gstruct.MatchFields(gstruct.IgnoreExtras,
+				gstruct.Fields{"InternalID": gomega.Equal(document.InternalID)},
+			))))
+		})
+
+		ginkgo.It("Should download a document", func(ctx ginkgo.SpecContext) {
+			gomega.Expect(run(ctx, "get", "/avis.txt", "-")).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).To(gomega.Equal("the content"))
+
+			gomega.Expect(run(ctx, "get", "/avis.txt", dir)).To(gomega.Succeed())
+			gomega.Expect(os.ReadFile(filepath.Join(dir, "avis.txt"))).To(gomega.BeEquivalentTo("the content"))
+		})
+
+		ginkgo.It("Should upload a document", func(ctx ginkgo.SpecContext) {
+			file := filepath.Join(dir, "upload.txt")
+			gomega.Expect(os.WriteFile(file, []byte("uploaded"), 0o600)).To(gomega.Succeed())
+
+			gomega.Expect(run(ctx, "put", "-name", "new.txt", file, "/Impôts/2024")).To(gomega.Succeed())
+			gomega.Expect(run(ctx, "get", "/Impôts/2024/new.txt", "-")).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).To(gomega.Equal("uploaded"))
+		})
+
+		ginkgo.It("Should move a document", func(ctx ginkgo.SpecContext) {
+			gomega.Expect(run(ctx, "mv", "/avis.txt", "/Impôts")).To(gomega.Succeed())
+			gomega.Expect(run(ctx, "get", "/Impôts/avis.txt", "-")).To(gomega.Succeed())
+			gomega.Expect(run(ctx, "get", "/avis.txt", "-")).To(gomega.MatchError(digiposte.ErrNotFound))
+		})
+
+		ginkgo.It("Should trash, restore and purge documents", func(ctx ginkgo.SpecContext) {
+			gomega.Expect(run(ctx, "rm", "/avis.txt", "/Impôts")).To(gomega.Succeed())
+			gomega.Expect(run(ctx, "ls", "/avis.txt")).To(gomega.MatchError(digiposte.ErrNotFound))
+
+			gomega.Expect(run(ctx, "trash", "ls")).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).To(gomega.ContainSubstring("avis.txt"))
+			gomega.Expect(stdout.String()).To(gomega.ContainSubstring("Impôts/"))
+
+			gomega.Expect(run(ctx, "trash", "restore", "Impôts")).To(gomega.Succeed())
+			gomega.Expect(run(ctx, "ls", "/Impôts/2024")).To(gomega.Succeed())
+
+			gomega.Expect(run(ctx, "purge", "avis.txt")).To(gomega.Succeed())
+			gomega.Expect(run(ctx, "trash", "ls", "-json")).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).ToNot(gomega.ContainSubstring("avis.txt"))
+
+			gomega.Expect(run(ctx, "rm", "/Impôts")).To(gomega.Succeed())
+			gomega.Expect(run(ctx, "trash", "empty")).To(gomega.Succeed())
+			gomega.Expect(run(ctx, "trash", "ls", "-json")).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).ToNot(gomega.ContainSubstring("Impôts"))
+		})
+
+		ginkgo.It("Should tag a document", func(ctx ginkgo.SpecContext) {
+			gomega.Expect(run(ctx, "tag", "/avis.txt", "taxes", "2024")).To(gomega.Succeed())
+			gomega.Expect(run(ctx, "tag", "-json")).To(gomega.Succeed())
+
+			tags := make(map[string]int)
+			gomega.Expect(json.Unmarshal(stdout.Bytes(), &tags)).To(gomega.Succeed())
+			gomega.Expect(tags).To(gomega.Equal(map[string]int{"taxes": 1, "2024": 1}))
+		})
+
+		ginkgo.It("Should manage shares", func(ctx ginkgo.SpecContext) {
+			gomega.Expect(run(ctx, "share", "create", "-json", "-title", "taxes", "-code", "1234", "/avis.txt")).
+				To(gomega.Succeed())
+
+			share := new(shareWithDocuments)
+			gomega.Expect(json.Unmarshal(stdout.Bytes(), share)).To(gomega.Succeed())
+			gomega.Expect(share.DocumentIDs).To(gomega.ConsistOf(document.InternalID))
+
+			gomega.Expect(run(ctx, "share", "list")).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).To(gomega.ContainSubstring(string(share.InternalID)))
+
+			gomega.Expect(run(ctx, "share", "rm", string(share.InternalID))).To(gomega.Succeed())
+			gomega.Expect(run(ctx, "share", "list")).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).To(gomega.BeEmpty())
+		})
+
+		ginkgo.It("Should back up the account", func(ctx ginkgo.SpecContext) {
+			archive := filepath.Join(dir, "backup.tar.gz")
+
+			gomega.Expect(run(ctx, "backup", "-json", archive)).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).To(gomega.ContainSubstring(`"documents": 1`))
+			gomega.Expect(archive).To(gomega.BeAnExistingFile())
+
+			gomega.Expect(run(ctx, "backup", archive)).ToNot(gomega.Succeed(), "the archive is not overwritten")
+			gomega.Expect(run(ctx, "backup", filepath.Join(dir, "backup.rar"))).To(gomega.HaveOccurred())
+		})
+
+		ginkgo.It("Should back up the account incrementally", func(ctx ginkgo.SpecContext) {
+			full := filepath.Join(dir, "full.zip")
+
+			gomega.Expect(run(ctx, "backup", full)).To(gomega.Succeed())
+
+			server.AddDocument(digiposte.RootFolderID, "new.txt", []byte("new"), digiposte.LocationSafe)
+
+			incremental := filepath.Join(dir, "incremental.zip")
+
+			gomega.Expect(run(ctx, "backup", "-since", full, incremental)).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).To(gomega.ContainSubstring("Backed up 2 documents (1 archived)"))
+
+			gomega.Expect(run(ctx, "restore", "-dry-run", incremental)).ToNot(gomega.Succeed())
+			gomega.Expect(run(ctx, "restore", "-dry-run", incremental, full)).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).To(gomega.ContainSubstring("Would restore 2 documents"))
+		})
+
+		ginkgo.It("Should restore a backup", func(ctx ginkgo.SpecContext) {
+			archive := filepath.Join(dir, "backup.zip")
+			mapping := filepath.Join(dir, "mapping.json")
+
+			gomega.Expect(run(ctx, "backup", archive)).To(gomega.Succeed())
+			gomega.Expect(run(ctx, "restore", "-dry-run", "-into", "Restored", archive)).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).To(gomega.ContainSubstring("Would restore 1 documents"))
+
+			gomega.Expect(run(ctx, "restore", "-json", "-dry-run", "-into", "Restored", archive)).To(gomega.Succeed())
+
+			summary := make(map[string]interface{})
+			gomega.Expect(json.Unmarshal(stdout.Bytes(), &summary)).To(gomega.Succeed())
+			gomega.Expect(summary).To(gomega.Equal(map[string]interface{}{
+				"dry_run":         true,
+				"created_folders": 2.0,
+				"uploaded":        1.0,
+				"skipped":         0.0,
+			}))
+
+			gomega.Expect(run(ctx, "restore", "-into", "Restored", "-mapping", mapping, archive)).To(gomega.Succeed())
+			gomega.Expect(run(ctx, "get", "/Restored/avis.txt", "-")).To(gomega.Succeed())
+
+			data, err := os.ReadFile(mapping)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(string(data)).To(gomega.ContainSubstring(string(document.InternalID)))
+		})
+
+		ginkgo.It("Should watch the inbox", func(ctx ginkgo.SpecContext) {
+			cursor := filepath.Join(dir, "cursor.json")
+
+			gomega.Expect(run(ctx, "watch", "-once", "-cursor", cursor)).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).To(gomega.BeEmpty())
+
+			bill := server.AddDocument(digiposte.RootFolderID, "bill.pdf", []byte("bill"), digiposte.LocationInbox)
+
+			gomega.Expect(run(ctx, "watch", "-once", "-cursor", cursor)).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).To(gomega.Equal("added  " + string(bill.InternalID) + "  bill.pdf\n"))
+		})
+
+		ginkgo.It("Should stop watching when an event cannot be printed", func(ctx ginkgo.SpecContext) {
+			cursor := filepath.Join(dir, "cursor.json")
+
+			server.AddDocument(digiposte.RootFolderID, "bill.pdf", []byte("bill"), digiposte.LocationInbox)
+
+			runCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+
+			gomega.Expect(runWith(runCtx, failingWriter{}, "watch", "-interval", "1ms", "-cursor", cursor)).
+				To(gomega.MatchError(errWrite))
+			gomega.Expect(cursor).ToNot(gomega.BeAnExistingFile())
+
+			gomega.Expect(run(ctx, "watch", "-once", "-cursor", cursor)).To(gomega.Succeed())
+			gomega.Expect(stdout.String()).To(gomega.ContainSubstring("bill.pdf"))
+		})
+
+		ginkgo.It("Should reject ambiguous names", func(ctx ginkgo.SpecContext) {
+			server.AddDocument(digiposte.RootFolderID, "avis.txt", []byte("other"), digiposte.LocationSafe)
+
+			gomega.Expect(run(ctx, "get", "/avis.txt", "-")).To(gomega.MatchError(digiposte.ErrAmbiguousPath))
+		})
+	})
+
+	ginkgo.It("Should reject unknown commands", func(ctx ginkgo.SpecContext) {
+		var usageErr *usageError
+
+		gomega.Expect(errors.As(run(ctx, "share", "unknown"), &usageErr)).To(gomega.BeTrue())
+		gomega.Expect(usageErr.Command).To(gomega.Equal("share"))
+	})
+})
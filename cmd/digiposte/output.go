@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+// print writes the value as JSON when -json is set, or calls human to write a readable output.
+func (a *app) print(value interface{}, human func(writer io.Writer)) error {
+	if a.json {
+		encoder := json.NewEncoder(a.stdout)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(value); err != nil {
+			return fmt.Errorf("encode result: %w", err)
+		}
+
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+
+	human(writer)
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("write result: %w", err)
+	}
+
+	return nil
+}
+
+// listing is the content of a folder.
+type listing struct {
+	Folders   []*digiposte.Folder   `json:"folders"`
+	Documents []*digiposte.Document `json:"documents"`
+}
+
+func (l *listing) write(writer io.Writer) {
+	for _, folder := range l.Folders {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s/\n", folder.InternalID, formatTime(folder.CreatedAt), "-", folder.Name)
+	}
+
+	for _, document := range l.Documents {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n",
+			document.InternalID, formatTime(document.CreatedAt), formatSize(document.Size), document.Name)
+	}
+}
+
+func formatTime(date time.Time) string {
+	if date.IsZero() {
+		return "-"
+	}
+
+	return date.Local().Format("2006-01-02 15:04")
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value, exp := float64(size)/unit, 0
+
+	for value >= unit && exp < 4 {
+		value /= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", value, "KMGTP"[exp])
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+// resolveAll returns the IDs of the documents and folders at the given paths.
+func resolveAll(
+	ctx context.Context,
+	resolver *digiposte.Resolver,
+	paths []string,
+) ([]digiposte.DocumentID, []digiposte.FolderID, error) {
+	var (
+		documentIDs []digiposte.DocumentID
+		folderIDs   []digiposte.FolderID
+	)
+
+	for _, path := range paths {
+		found, err := resolver.Resolve(ctx, path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("resolve: %w", err)
+		}
+
+		switch {
+		case found.Document != nil:
+			documentIDs = append(documentIDs, found.Document.InternalID)
+		case found.Path == ".":
+			return nil, nil, fmt.Errorf("%s: %w: the root folder", path, digiposte.ErrValidation)
+		default:
+			folderIDs = append(folderIDs, found.Folder.InternalID)
+		}
+	}
+
+	return documentIDs, folderIDs, nil
+}
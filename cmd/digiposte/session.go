@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/holyhope/digiposte-go-sdk/login"
+	"github.com/holyhope/digiposte-go-sdk/login/chrome"
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+// defaultSessionFile returns the file the session is saved to, in the user configuration directory.
+func defaultSessionFile() string {
+	if file := os.Getenv("DIGIPOSTE_SESSION"); file != "" {
+		return file
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ".digiposte-session.json"
+	}
+
+	return filepath.Join(dir, "digiposte", "session.json")
+}
+
+// sessionStore returns the store of the session file.
+// The session is encrypted when DIGIPOSTE_SESSION_PASSPHRASE is set.
+func (a *app) sessionStore() digiposte.SessionStore { //nolint:ireturn
+	if passphrase := os.Getenv("DIGIPOSTE_SESSION_PASSPHRASE"); passphrase != "" {
+		return digiposte.NewEncryptedFileSessionStore(a.sessionFile, []byte(passphrase))
+	}
+
+	return digiposte.NewFileSessionStore(a.sessionFile)
+}
+
+// client returns a client authenticated with the saved session.
+// If the session is missing or expired, the user is logged in again.
+func (a *app) client(ctx context.Context) (*digiposte.Client, error) {
+	return a.newClient(ctx, nil)
+}
+
+// newClient returns a client starting from the given session,
+// or from the saved one if it is nil.
+func (a *app) newClient(ctx context.Context, session *digiposte.Session) (*digiposte.Client, error) {
+	method := a.loginMethod
+	if method == nil {
+		chromeMethod, err := chrome.New(
+			chrome.WithURL(a.documentURL),
+			chrome.WithChromeVersion(ctx, 0, nil),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("new chrome login method: %w", err)
+		}
+
+		method = chromeMethod
+	}
+
+	client, err := digiposte.NewAuthenticatedClient(ctx, new(http.Client), &digiposte.Config{
+		APIURL:      a.apiURL,
+		DocumentURL: a.documentURL,
+		LoginMethod: method,
+		Credentials: &login.Credentials{
+			Username:  os.Getenv("DIGIPOSTE_USERNAME"),
+			Password:  os.Getenv("DIGIPOSTE_PASSWORD"),
+			OTPSecret: os.Getenv("DIGIPOSTE_OTP_SECRET"),
+		},
+		SessionListener: nil,
+		PreviousSession: session,
+		SessionStore:    a.sessionStore(),
+		SessionSaveError: func(err error) {
+			fmt.Fprintf(a.stderr, "digiposte: save session: %v\n", err)
+		},
+		ClientOptions: a.clientOptions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("new client: %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+var (
+	errMissingTitle = errors.New("-title is required")
+	errInvalidDate  = errors.New("invalid date, expected 2006-01-02 or 2006-01-02T15:04:05Z07:00")
+)
+
+// dateFlag is a flag accepting a date (2006-01-02) or a date and time (RFC 3339).
+type dateFlag struct {
+	time.Time
+}
+
+func (f *dateFlag) String() string {
+	if f.IsZero() {
+		return ""
+	}
+
+	return f.Format(time.RFC3339)
+}
+
+func (f *dateFlag) Set(value string) error {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if date, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			f.Time = date
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%q: %w", value, errInvalidDate)
+}
+
+// shareWithDocuments is the result of the share create command.
+type shareWithDocuments struct {
+	*digiposte.Share
+
+	DocumentIDs []digiposte.DocumentID `json:"document_ids"`
+}
+
+func (a *app) createShare(ctx context.Context, args []string) error {
+	var (
+		title, code string
+		start, end  dateFlag
+	)
+
+	args, err := a.parseFlags("share create", "-title title [-code code] [-start date] [-end date] [path]...", args, 0, -1,
+		func(flags *flag.FlagSet) {
+			flags.StringVar(&title, "title", "", "title of the share")
+			flags.StringVar(&code, "code", "", "security code of the share")
+			flags.Var(&start, "start", "start date of the share (default: now)")
+			flags.Var(&end, "end", "end date of the share (default: never)")
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if title == "" {
+		return &usageError{Command: "share create", Message: errMissingTitle.Error()}
+	}
+
+	if start.IsZero() {
+		start.Time = time.Now()
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	resolver := client.NewResolver()
+	documentIDs := make([]digiposte.DocumentID, 0, len(args))
+
+	for _, path := range args {
+		document, err := resolver.ResolveDocument(ctx, path)
+		if err != nil {
+			return fmt.Errorf("resolve: %w", err)
+		}
+
+		documentIDs = append(documentIDs, document.InternalID)
+	}
+
+	share, err := client.CreateShare(ctx, start.Time, end.Time, title, code)
+	if err != nil {
+		return fmt.Errorf("create share: %w", err)
+	}
+
+	if len(documentIDs) > 0 {
+		if err := client.SetShareDocuments(ctx, share.InternalID, documentIDs); err != nil {
+			return fmt.Errorf("set share documents: %w", err)
+		}
+	}
+
+	return a.print(&shareWithDocuments{Share: share, DocumentIDs: documentIDs}, func(writer io.Writer) {
+		fmt.Fprintf(writer, "Created share %s with %d documents\n", share.InternalID, len(documentIDs))
+		fmt.Fprintf(writer, "URL:\t%s\n", share.ShortURL)
+
+		if share.SecurityCode != "" {
+			fmt.Fprintf(writer, "Code:\t%s\n", share.SecurityCode)
+		}
+	})
+}
+
+func (a *app) listShares(ctx context.Context, args []string) error {
+	if _, err := a.parseFlags("share list", "", args, 0, 0, nil); err != nil {
+		return err
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := client.ListShares(ctx)
+	if err != nil {
+		return fmt.Errorf("list shares: %w", err)
+	}
+
+	shares := result.SenderShares
+	if shares == nil {
+		shares = []digiposte.Share{}
+	}
+
+	return a.print(shares, func(writer io.Writer) {
+		for _, share := range shares {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n",
+				share.InternalID, formatTime(share.StartDate), formatTime(share.EndDate), share.ShortURL, share.Title)
+		}
+	})
+}
+
+func (a *app) removeShares(ctx context.Context, args []string) error {
+	args, err := a.parseFlags("share rm", "<ID>...", args, 1, -1, nil)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	shareIDs := make([]digiposte.ShareID, 0, len(args))
+
+	for _, id := range args {
+		if err := client.DeleteShare(ctx, digiposte.ShareID(id)); err != nil {
+			return fmt.Errorf("delete share %s: %w", id, err)
+		}
+
+		shareIDs = append(shareIDs, digiposte.ShareID(id))
+	}
+
+	return a.print(shareIDs, func(writer io.Writer) {
+		fmt.Fprintf(writer, "Deleted %d shares\n", len(shareIDs))
+	})
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+func (a *app) tag(ctx context.Context, args []string) error {
+	args, err := a.parseFlags("tag", "[<path> <tag>...]", args, 0, -1, nil)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		return &usageError{Command: "tag", Message: "usage: digiposte tag [<path> <tag>...]"}
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return a.listTags(ctx, client)
+	}
+
+	document, err := client.NewResolver().ResolveDocument(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("resolve: %w", err)
+	}
+
+	tags := make([]digiposte.DocumentTag, 0, len(args)-1)
+
+	for _, tag := range args[1:] {
+		tags = append(tags, digiposte.DocumentTag(tag))
+	}
+
+	if err := client.MultiTag(ctx, map[digiposte.DocumentID][]digiposte.DocumentTag{
+		document.InternalID: tags,
+	}); err != nil {
+		return fmt.Errorf("tag document: %w", err)
+	}
+
+	return a.print(map[digiposte.DocumentID][]digiposte.DocumentTag{document.InternalID: tags}, func(writer io.Writer) {
+		fmt.Fprintf(writer, "Tagged %s with %d tags\n", document.Name, len(tags))
+	})
+}
+
+func (a *app) listTags(ctx context.Context, client *digiposte.Client) error {
+	result, err := client.UserTags(ctx)
+	if err != nil {
+		return fmt.Errorf("get user tags: %w", err)
+	}
+
+	tags := make([]digiposte.DocumentTag, 0, len(result.Tags))
+
+	for tag := range result.Tags {
+		tags = append(tags, tag)
+	}
+
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+
+	return a.print(result.Tags, func(writer io.Writer) {
+		for _, tag := range tags {
+			fmt.Fprintf(writer, "%s\t%d\n", tag, result.Tags[tag])
+		}
+	})
+}
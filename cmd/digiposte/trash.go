@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+// trashListing returns the documents and the top-most folders in the trash.
+func trashListing(ctx context.Context, client *digiposte.Client) (*listing, error) {
+	folders, err := client.GetTrashedFolders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get trashed folders: %w", err)
+	}
+
+	documents, err := client.TrashedDocumentsIter(ctx).All()
+	if err != nil {
+		return nil, fmt.Errorf("get trashed documents: %w", err)
+	}
+
+	if documents == nil {
+		documents = []*digiposte.Document{}
+	}
+
+	return &listing{
+		Folders:   folders.Folders,
+		Documents: documents,
+	}, nil
+}
+
+// resolveTrash returns the IDs of the items of the trash matching the given names or IDs.
+func resolveTrash(
+	ctx context.Context,
+	client *digiposte.Client,
+	names []string,
+) ([]digiposte.DocumentID, []digiposte.FolderID, error) {
+	trash, err := trashListing(ctx, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		documentIDs []digiposte.DocumentID
+		folderIDs   []digiposte.FolderID
+	)
+
+	for _, name := range names {
+		var (
+			matchingDocuments []digiposte.DocumentID
+			matchingFolders   []digiposte.FolderID
+		)
+
+		for _, document := range trash.Documents {
+			if document.Name == name || string(document.InternalID) == name {
+				matchingDocuments = append(matchingDocuments, document.InternalID)
+			}
+		}
+
+		for _, folder := range trash.Folders {
+			if folder.Name == name || string(folder.InternalID) == name {
+				matchingFolders = append(matchingFolders, folder.InternalID)
+			}
+		}
+
+		switch count := len(matchingDocuments) + len(matchingFolders); count {
+		case 0:
+			return nil, nil, fmt.Errorf("trash: %s: %w", name, digiposte.ErrNotFound)
+		case 1:
+			documentIDs = append(documentIDs, matchingDocuments...)
+			folderIDs = append(folderIDs, matchingFolders...)
+		default:
+			return nil, nil, fmt.Errorf("trash: %s: %w: %d items have this name, use their ID",
+				name, digiposte.ErrAmbiguousPath, count)
+		}
+	}
+
+	return documentIDs, folderIDs, nil
+}
+
+func (a *app) listTrash(ctx context.Context, args []string) error {
+	if _, err := a.parseFlags("trash ls", "", args, 0, 0, nil); err != nil {
+		return err
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := trashListing(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	return a.print(result, result.write)
+}
+
+func (a *app) restore(ctx context.Context, args []string) error {
+	args, err := a.parseFlags("trash restore", "<name or ID>...", args, 1, -1, nil)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	documentIDs, folderIDs, err := resolveTrash(ctx, client, args)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Restore(ctx, documentIDs, folderIDs); err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	result := &treeResult{DocumentIDs: documentIDs, FolderIDs: folderIDs}
+
+	return a.print(result, result.writer("Restored"))
+}
+
+func (a *app) purge(ctx context.Context, args []string) error {
+	args, err := a.parseFlags("purge", "<name or ID>...", args, 1, -1, nil)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	documentIDs, folderIDs, err := resolveTrash(ctx, client, args)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Delete(ctx, documentIDs, folderIDs); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	result := &treeResult{DocumentIDs: documentIDs, FolderIDs: folderIDs}
+
+	return a.print(result, result.writer("Deleted permanently"))
+}
+
+func (a *app) emptyTrash(ctx context.Context, args []string) error {
+	if _, err := a.parseFlags("trash empty", "", args, 0, 0, nil); err != nil {
+		return err
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := client.EmptyTrash(ctx); err != nil {
+		return fmt.Errorf("empty trash: %w", err)
+	}
+
+	return nil
+}
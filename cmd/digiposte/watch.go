@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+	"github.com/holyhope/digiposte-go-sdk/v1/watch"
+)
+
+// watchEvent is an event printed by the watch command.
+type watchEvent struct {
+	Kind       string               `json:"kind"`
+	DocumentID digiposte.DocumentID `json:"document_id"`
+	Name       string               `json:"name"`
+	Tags       []string             `json:"tags"`
+}
+
+func (a *app) watch(ctx context.Context, args []string) error {
+	var (
+		interval       time.Duration
+		cursor         string
+		ignoreExisting bool
+		once           bool
+	)
+
+	_, err := a.parseFlags("watch", "[-interval d] [-cursor file.json] [-ignore-existing] [-once]", args, 0, 0,
+		func(flags *flag.FlagSet) {
+			flags.DurationVar(&interval, "interval", watch.DefaultInterval, "time between two polls of the inbox")
+			flags.StringVar(&cursor, "cursor", "", "file to save the documents seen, to resume from")
+			flags.BoolVar(&ignoreExisting, "ignore-existing", false, "do not report the documents already in the inbox")
+			flags.BoolVar(&once, "once", false, "poll once and exit")
+		})
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	options := []watch.Option{
+		watch.WithInterval(interval),
+		watch.WithErrorHandler(func(err error) {
+			fmt.Fprintf(a.stderr, "watch: %v\n", err)
+		}),
+	}
+
+	if cursor != "" {
+		options = append(options, watch.WithCursorFile(cursor))
+	}
+
+	if ignoreExisting {
+		options = append(options, watch.IgnoreExisting())
+	}
+
+	watcher := watch.New(client, options...)
+
+	// A failed print stops the watch, so that the events are not silently lost.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var printErr error
+
+	handle := func(event watch.Event) {
+		if printErr = a.printEvent(event); printErr != nil {
+			cancel()
+		}
+	}
+
+	if once {
+		err = watcher.Poll(ctx, handle)
+	} else {
+		err = watcher.Run(ctx, handle)
+	}
+
+	switch {
+	case printErr != nil:
+		return printErr
+	case !once && errors.Is(err, context.Canceled):
+		return nil
+	case err != nil:
+		return fmt.Errorf("watch: %w", err)
+	default:
+		return nil
+	}
+}
+
+func (a *app) printEvent(event watch.Event) error {
+	printed := &watchEvent{
+		Kind:       event.Kind.String(),
+		DocumentID: event.DocumentID,
+		Name:       "",
+		Tags:       nil,
+	}
+
+	if event.Document != nil {
+		printed.Name = event.Document.Name
+		printed.Tags = event.Document.UserTags
+	} else {
+		printed.Name = event.Previous.Name
+		printed.Tags = event.Previous.Tags
+	}
+
+	return a.print(printed, func(writer io.Writer) {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", printed.Kind, printed.DocumentID, printed.Name)
+	})
+}
@@ -1,6 +1,6 @@
 module github.com/holyhope/digiposte-go-sdk
 
-go 1.20
+go 1.21
 
 require (
 	github.com/Davincible/chromedp-undetected v1.3.8
@@ -10,6 +10,7 @@ require (
 	github.com/onsi/ginkgo/v2 v2.13.0
 	github.com/onsi/gomega v1.30.0
 	github.com/pquerna/otp v1.4.0
+	golang.org/x/crypto v0.14.0
 	golang.org/x/oauth2 v0.13.0
 	golang.org/x/time v0.5.0
 )
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WriteFile writes the file atomically: write fills a temporary file of the same directory,
+// named after the pattern as by os.CreateTemp, which then replaces the file.
+// A non-zero modTime is set as the access and modification times of the file.
+func WriteFile(path, pattern string, modTime time.Time, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), pattern)
+	if err != nil {
+		return fmt.Errorf("create temporary file: %w", err)
+	}
+
+	// Remove the temporary file if it was not renamed.
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err := write(tmp); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temporary file: %w", err)
+	}
+
+	if !modTime.IsZero() {
+		if err := os.Chtimes(tmp.Name(), modTime, modTime); err != nil {
+			return fmt.Errorf("set times: %w", err)
+		}
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename temporary file: %w", err)
+	}
+
+	return nil
+}
+
+// WriteContent returns a write function of WriteFile writing the content.
+func WriteContent(content []byte) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		if _, err := w.Write(content); err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+)
+
+// DiscardLogger returns a logger that drops every record.
+func DiscardLogger() *slog.Logger {
+	return slog.New(discardHandler{})
+}
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h } //nolint:ireturn
+func (h discardHandler) WithGroup(string) slog.Handler           { return h } //nolint:ireturn
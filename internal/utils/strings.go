@@ -0,0 +1,24 @@
+package utils
+
+// SameElements reports whether both slices hold the same strings, in any order, counting the duplicates.
+func SameElements(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	count := make(map[string]int, len(a))
+
+	for _, element := range a {
+		count[element]++
+	}
+
+	for _, element := range b {
+		if count[element] == 0 {
+			return false
+		}
+
+		count[element]--
+	}
+
+	return true
+}
@@ -3,7 +3,7 @@ package chrome
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"sync/atomic"
 	"time"
@@ -30,13 +30,15 @@ func (c *chromeLogin) login( //nolint:nonamedreturns
 
 	defer c.WrapError(independentChromeCtx, &finalErr)
 
+	ctx = withLogAttrs(ctx, slog.String("url", c.url))
+
 	if err := resolve(ctx, &firstScreen{
 		URL: c.url,
 	}); err != nil {
 		return nil, nil, fmt.Errorf("first screen: %w", err)
 	}
 
-	infoLogger(ctx).Printf("Page %q loaded\n", c.url)
+	logger(ctx).Info("Page loaded")
 
 	return c.resolveLogin(ctx, creds)
 }
@@ -60,7 +62,7 @@ func (c *chromeLogin) WrapError(ctx context.Context, errPtr *error) {
 	if err := chromedp.Run(ctx,
 		chromedp.Location(&currentLocation),
 	); err != nil {
-		errorLogger(ctx).Printf("Failed to get current location: %v\n", err)
+		logger(ctx).Error("Failed to get current location", slog.Any("error", err))
 	} else {
 		*errPtr = &WithLocationError{
 			Err:      *errPtr,
@@ -142,8 +144,7 @@ type chromeLogin struct {
 	refreshFrequency   time.Duration
 	timeout            time.Duration
 
-	infoLogger  *log.Logger
-	errorLogger *log.Logger
+	logger *slog.Logger
 
 	binaryPath string
 }
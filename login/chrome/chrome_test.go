@@ -2,7 +2,7 @@ package chrome_test
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path"
 	"time"
@@ -44,10 +44,7 @@ var _ = Describe("Login", func() {
 				chrome.WithURL(os.Getenv("DIGIPOSTE_URL")),
 				chrome.WithCookies(nil),
 				chrome.WithRefreshFrequency(500*time.Millisecond), // Reduce the test duration
-				chrome.WithLoggers(
-					log.New(GinkgoWriter, "[INFO] ", log.Ldate|log.Ltime|log.Lmsgprefix),
-					log.New(GinkgoWriter, "[ERRO] ", log.Ldate|log.Ltime|log.Lmsgprefix),
-				),
+				chrome.WithLogger(slog.New(slog.NewTextHandler(GinkgoWriter, nil))),
 				chrome.WithScreenShortOnError(),
 				chrome.WithTimeout(3*time.Minute),
 				chrome.WithBinary(chromeBinary),
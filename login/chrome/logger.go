@@ -2,35 +2,104 @@ package chrome
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"log/slog"
+	"strings"
 )
 
-var contextErrorLoggerKey = "error-logger" //nolint:gochecknoglobals
+type loggerKey struct{}
 
-func errorLogger(ctx context.Context) *log.Logger {
-	logger, ok := ctx.Value(&contextErrorLoggerKey).(*log.Logger)
-	if !ok {
-		panic("no error logger")
+// logger returns the logger of the context, or the default logger if there is none.
+func logger(ctx context.Context) *slog.Logger {
+	if lgr, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && lgr != nil {
+		return lgr
 	}
 
-	return log.New(logger.Writer(), logger.Prefix(), logger.Flags())
+	return slog.Default()
 }
 
-func withErrorLogger(ctx context.Context, logger *log.Logger) context.Context {
-	return context.WithValue(ctx, &contextErrorLoggerKey, log.New(logger.Writer(), logger.Prefix(), logger.Flags()))
+func contextWithLogger(ctx context.Context, lgr *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, lgr)
 }
 
-var contextInfoLoggerKey = "info-logger" //nolint:gochecknoglobals
+// withLogAttrs returns a context whose logger has the given attributes.
+func withLogAttrs(ctx context.Context, args ...any) context.Context {
+	return contextWithLogger(ctx, logger(ctx).With(args...))
+}
+
+// printf returns a printf-like function logging its message at the given level.
+func printf(lgr *slog.Logger, level slog.Level) func(format string, args ...any) {
+	return func(format string, args ...any) {
+		lgr.Log(context.Background(), level, strings.TrimSuffix(fmt.Sprintf(format, args...), "\n"))
+	}
+}
+
+// newLegacyLogger returns a logger writing the records below the error level to info,
+// and the others to errorLgr. A nil logger is replaced by the standard logger.
+func newLegacyLogger(info, errorLgr *log.Logger) *slog.Logger {
+	return slog.New(&levelHandler{
+		info:  newLegacyHandler(info),
+		error: newLegacyHandler(errorLgr),
+	})
+}
+
+func newLegacyHandler(lgr *log.Logger) slog.Handler { //nolint:ireturn
+	if lgr == nil {
+		lgr = log.Default()
+	}
+
+	return slog.NewTextHandler(&logWriter{logger: lgr}, &slog.HandlerOptions{
+		AddSource: false,
+		Level:     slog.LevelDebug,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			// The time is already printed by the log.Logger, according to its flags.
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{Key: "", Value: slog.Value{}}
+			}
+
+			return attr
+		},
+	})
+}
+
+// levelHandler dispatches the records to two handlers depending on their level.
+type levelHandler struct {
+	info  slog.Handler
+	error slog.Handler
+}
 
-func infoLogger(ctx context.Context) *log.Logger {
-	logger, ok := ctx.Value(&contextInfoLoggerKey).(*log.Logger)
-	if !ok {
-		panic("no info logger")
+func (h *levelHandler) handler(level slog.Level) slog.Handler { //nolint:ireturn
+	if level >= slog.LevelError {
+		return h.error
 	}
 
-	return log.New(logger.Writer(), logger.Prefix(), logger.Flags())
+	return h.info
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler(level).Enabled(ctx, level)
+}
+
+func (h *levelHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.handler(record.Level).Handle(ctx, record) //nolint:wrapcheck
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler { //nolint:ireturn
+	return &levelHandler{info: h.info.WithAttrs(attrs), error: h.error.WithAttrs(attrs)}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler { //nolint:ireturn
+	return &levelHandler{info: h.info.WithGroup(name), error: h.error.WithGroup(name)}
 }
 
-func withInfoLogger(ctx context.Context, logger *log.Logger) context.Context {
-	return context.WithValue(ctx, &contextInfoLoggerKey, log.New(logger.Writer(), logger.Prefix(), logger.Flags()))
+// logWriter writes each line to a log.Logger, keeping its prefix and flags.
+type logWriter struct {
+	logger *log.Logger
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.logger.Print(strings.TrimSuffix(string(p), "\n"))
+
+	return len(p), nil
 }
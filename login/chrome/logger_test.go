@@ -0,0 +1,43 @@
+package chrome
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"log/slog"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("logger", func() {
+	ginkgo.It("Should not panic without logger", func() {
+		gomega.Expect(logger(context.Background())).To(gomega.BeIdenticalTo(slog.Default()))
+	})
+
+	ginkgo.It("Should add attributes without changing the parent logger", func() {
+		buffer := new(bytes.Buffer)
+		parent := contextWithLogger(context.Background(), slog.New(slog.NewTextHandler(buffer, nil)))
+
+		logger(withLogAttrs(parent, slog.String("screen", "OTP screen"))).Info("child")
+		logger(parent).Info("parent")
+
+		gomega.Expect(buffer.String()).To(gomega.ContainSubstring(`msg=child screen="OTP screen"`))
+		gomega.Expect(buffer.String()).To(gomega.MatchRegexp(`msg=parent\n$`))
+	})
+
+	ginkgo.Describe("newLegacyLogger", func() {
+		ginkgo.It("Should write the errors and the other records to their logger", func() {
+			info, errs := new(bytes.Buffer), new(bytes.Buffer)
+
+			lgr := newLegacyLogger(log.New(info, "[INFO] ", 0), log.New(errs, "[ERRO] ", 0)).
+				With(slog.Int("pid", 42))
+
+			lgr.Info("started", slog.Int("attempt", 1))
+			lgr.Error("failed")
+
+			gomega.Expect(info.String()).To(gomega.Equal("[INFO] level=INFO msg=started pid=42 attempt=1\n"))
+			gomega.Expect(errs.String()).To(gomega.Equal("[ERRO] level=ERROR msg=failed pid=42\n"))
+		})
+	})
+})
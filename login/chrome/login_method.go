@@ -3,7 +3,7 @@ package chrome
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -54,7 +54,9 @@ func (c *chromeMethod) Login(ctx context.Context, creds *login.Credentials) (*oa
 
 	pid := chromedp.FromContext(independentChromeCtx).Browser.Process().Pid
 
-	infoLogger(independentChromeCtx).Printf("Chrome started. PID: %d...\n", pid)
+	independentChromeCtx = withLogAttrs(independentChromeCtx, slog.Int("pid", pid))
+
+	logger(independentChromeCtx).Info("Chrome started")
 
 	return chrome.login(ctx, independentChromeCtx, creds)
 }
@@ -76,8 +78,7 @@ func (c *chromeMethod) newChromeLogin(
 		url:                settings.DefaultDocumentURL,
 		cookies:            nil,
 		screenShortOnError: false,
-		infoLogger:         log.Default(),
-		errorLogger:        log.Default(),
+		logger:             slog.Default(),
 		timeout:            0,
 		binaryPath:         "",
 	}
@@ -91,16 +92,15 @@ func (c *chromeMethod) newChromeLogin(
 	// Note: Do not inherit the context, so that we can cancel it independently.
 	independentChromeCtx, cancelCtx := context.WithCancel(context.Background())
 
-	independentChromeCtx = withInfoLogger(independentChromeCtx, chrome.infoLogger)
-	independentChromeCtx = withErrorLogger(independentChromeCtx, chrome.errorLogger)
+	independentChromeCtx = contextWithLogger(independentChromeCtx, chrome.logger)
 
 	independentChromeCtx, cancelChrome, err := cu.New(cu.NewConfig(append(chromeOpts,
 		cu.WithContext(independentChromeCtx),
 		cu.WithChromeBinary(chrome.binaryPath),
 		func(c *cu.Config) {
 			c.ContextOptions = append(c.ContextOptions,
-				chromedp.WithErrorf(chrome.errorLogger.Printf),
-				chromedp.WithLogf(chrome.infoLogger.Printf),
+				chromedp.WithErrorf(printf(chrome.logger, slog.LevelError)),
+				chromedp.WithLogf(printf(chrome.logger, slog.LevelInfo)),
 				chromedp.WithDebugf(func(_ string, _ ...interface{}) {
 					// do nothing
 				}),
@@ -128,12 +128,12 @@ func closeChrome(ctx context.Context) {
 	defer cancel()
 
 	if err := chromedp.Cancel(ctx); err != nil {
-		lgr := errorLogger(ctx)
+		lgr := logger(ctx)
 
-		lgr.Printf("Failed to cancel chrome: %v\n", err)
+		lgr.Error("Failed to cancel chrome", slog.Any("error", err))
 
 		if err := proc.Kill(); err != nil {
-			lgr.Printf("Failed to kill chrome: %v\n", err)
+			lgr.Error("Failed to kill chrome", slog.Any("error", err))
 		}
 	}
 }
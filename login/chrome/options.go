@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"reflect"
 	"time"
@@ -199,27 +200,21 @@ func (e *WithScreenshotError) Unwrap() error {
 	return e.Err
 }
 
-// WithLoggers sets the loggers to be used for the login process.
-func WithLoggers(infoLgr, errorLgr *log.Logger) login.Option { //nolint:ireturn
-	return &withLoggers{
-		Info:  infoLgr,
-		Error: errorLgr,
-	}
+// WithLogger sets the logger to be used for the login process.
+// The records have the screen, attempt, url and pid attributes when relevant.
+// The default is slog.Default().
+func WithLogger(lgr *slog.Logger) login.Option { //nolint:ireturn
+	return &withLogger{Logger: lgr}
 }
 
-type withLoggers struct {
-	Info  *log.Logger
-	Error *log.Logger
+type withLogger struct {
+	Logger *slog.Logger
 }
 
-func (o *withLoggers) Apply(instance interface{}) error {
+func (o *withLogger) Apply(instance interface{}) error {
 	if chrome, ok := instance.(*chromeLogin); ok {
-		if o.Info != nil {
-			chrome.infoLogger = o.Info
-		}
-
-		if o.Error != nil {
-			chrome.errorLogger = o.Error
+		if o.Logger != nil {
+			chrome.logger = o.Logger
 		}
 
 		return nil
@@ -228,6 +223,15 @@ func (o *withLoggers) Apply(instance interface{}) error {
 	return &InvalidTypeOptionError{instance: instance}
 }
 
+// WithLoggers sets the loggers to be used for the login process.
+// The errors are written to errorLgr, the other records to infoLgr.
+// A nil logger is replaced by the standard logger.
+//
+// Deprecated: Use WithLogger.
+func WithLoggers(infoLgr, errorLgr *log.Logger) login.Option { //nolint:ireturn
+	return &withLogger{Logger: newLegacyLogger(infoLgr, errorLgr)}
+}
+
 // WithChromeVersion sets the version of chrome to be used for the login process.
 func WithChromeVersion(ctx context.Context, revision int, client *http.Client) login.Option { //nolint:ireturn
 	browser := launcher.NewBrowser()
@@ -252,7 +256,7 @@ type withChromeVersion struct {
 
 func (o *withChromeVersion) Apply(instance interface{}) error {
 	if chrome, ok := instance.(*chromeLogin); ok {
-		o.Browser.Logger = chrome.infoLogger
+		o.Browser.Logger = slog.NewLogLogger(chrome.logger.With("component", "launcher").Handler(), slog.LevelInfo)
 
 		path, err := o.Browser.Get()
 		if err != nil {
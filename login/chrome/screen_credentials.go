@@ -3,6 +3,7 @@ package chrome
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/chromedp/cdproto/cdp"
 	"github.com/chromedp/cdproto/input"
@@ -32,7 +33,7 @@ func (s *credentialsScreen) CurrentPageMatches(ctx context.Context) bool {
 		chromedp.NodeIDs(`form[name=login-form]`, &nodeIDs, chromedp.ByQuery, chromedp.AtLeast(0)),
 	)
 	if err != nil {
-		errorLogger(ctx).Printf("run: %v\n", err)
+		logger(ctx).Error("Failed to run in chrome", slog.Any("error", err))
 
 		return false
 	}
@@ -3,6 +3,7 @@ package chrome
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 
@@ -30,14 +31,14 @@ func (s *finalScreen) CurrentPageMatches(ctx context.Context) bool {
 	if err := chromedp.Run(ctx,
 		chromedp.Location(&currentLocation),
 	); err != nil {
-		errorLogger(ctx).Printf("run: %v\n", err)
+		logger(ctx).Error("Failed to run in chrome", slog.Any("error", err))
 
 		return false
 	}
 
 	currentURL, err := url.Parse(currentLocation)
 	if err != nil {
-		errorLogger(ctx).Printf("parse current location: %v\n", err)
+		logger(ctx).Error("Failed to parse current location", slog.Any("error", err))
 
 		return false
 	}
@@ -58,7 +59,7 @@ func (s *finalScreen) Do(ctx context.Context) error {
 
 	var expiryStr string
 
-	infoLogger(ctx).Println("Fetching token from browser...")
+	logger(ctx).Info("Fetching token from browser...")
 
 	if err := (&chromedp.Tasks{
 		chromedp.Poll(`sessionStorage.getItem("access_token")`, &token.AccessToken),
@@ -84,7 +85,7 @@ func (s *finalScreen) Do(ctx context.Context) error {
 		currentURL string
 	)
 
-	infoLogger(ctx).Println("Fetching cookies from browser...")
+	logger(ctx).Info("Fetching cookies from browser...")
 
 	if err := (&chromedp.Tasks{
 		chromedp.Location(&currentURL),
@@ -105,7 +106,7 @@ func (s *finalScreen) Do(ctx context.Context) error {
 		return fmt.Errorf("fetch cookies from browser: %w", err)
 	}
 
-	infoLogger(ctx).Printf("%d cookies fetched from %q\n", len(cookies), currentURL)
+	logger(ctx).Info("Cookies fetched", slog.Int("count", len(cookies)), slog.String("url", currentURL))
 
 	s.Token = token
 	s.Cookies = cookies
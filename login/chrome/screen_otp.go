@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/chromedp/cdproto/cdp"
@@ -29,7 +30,7 @@ func (s *otpScreen) CurrentPageMatches(ctx context.Context) bool {
 	if err := chromedp.Run(ctx,
 		chromedp.NodeIDs(`#otpCode`, &nodeIDs, chromedp.ByID, chromedp.AtLeast(0)),
 	); err != nil {
-		errorLogger(ctx).Printf("run: %v\n", err)
+		logger(ctx).Error("Failed to run in chrome", slog.Any("error", err))
 
 		return false
 	}
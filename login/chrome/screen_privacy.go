@@ -3,6 +3,7 @@ package chrome
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/chromedp/cdproto/cdp"
 	"github.com/chromedp/chromedp"
@@ -24,7 +25,7 @@ func (s *privacyScreen) CurrentPageMatches(ctx context.Context) bool {
 	if err := chromedp.Run(ctx,
 		chromedp.NodeIDs(`#footer_tc_privacy_button_3`, &nodeIDs, chromedp.ByID, chromedp.AtLeast(0)),
 	); err != nil {
-		errorLogger(ctx).Printf("run: %v\n", err)
+		logger(ctx).Error("Failed to run in chrome", slog.Any("error", err))
 
 		return false
 	}
@@ -3,6 +3,7 @@ package chrome
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/chromedp/cdproto/cdp"
 	"github.com/chromedp/chromedp"
@@ -23,7 +24,7 @@ func (s *trustedDeviceScreen) CurrentPageMatches(ctx context.Context) bool {
 		chromedp.NodeIDs(`#save-trusted-device-form`, &nodeIDs, chromedp.ByID, chromedp.AtLeast(0)),
 	)
 	if err != nil {
-		errorLogger(ctx).Printf("run: %v\n", err)
+		logger(ctx).Error("Failed to run in chrome", slog.Any("error", err))
 
 		return false
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -28,16 +29,10 @@ type Screens struct {
 func (s *Screens) Resolve(ctx context.Context) {
 	var waitGroup sync.WaitGroup
 
-	infoLgr, errorLgr := infoLogger(ctx), errorLogger(ctx)
-	infoPrefix, errorPrefix := infoLgr.Prefix(), errorLgr.Prefix()
-
-	defer infoLgr.Println("Stopped all resolvers")
+	defer logger(ctx).Info("Stopped all resolvers")
 
 	for _, screen := range s.screens {
-		infoLgr.SetPrefix(fmt.Sprintf("%s[%v] ", infoPrefix, screen))
-		errorLgr.SetPrefix(fmt.Sprintf("%s[%v] ", errorPrefix, screen))
-
-		ctx := withErrorLogger(withInfoLogger(ctx, infoLgr), errorLgr)
+		ctx := withLogAttrs(ctx, slog.String("screen", screen.String()))
 
 		waitGroup.Add(1)
 
@@ -55,10 +50,12 @@ func (s *Screens) run(ctx context.Context, screen Screen) {
 	refreshFrequency := time.NewTicker(s.refreshFrequency)
 	defer refreshFrequency.Stop()
 
-	infoLogger(ctx).Println("Started resolver...")
-	defer infoLogger(ctx).Println("Stopped resolver")
+	lgr := logger(ctx)
 
-	for !s.succeeded.Load() {
+	lgr.Info("Started resolver...")
+	defer lgr.Info("Stopped resolver")
+
+	for attempt := 1; !s.succeeded.Load(); {
 		select {
 		case <-ctx.Done():
 			return
@@ -70,7 +67,10 @@ func (s *Screens) run(ctx context.Context, screen Screen) {
 
 			ctx, cancel := context.WithTimeout(ctx, s.refreshFrequency)
 
-			infoLogger(ctx).Println("Resolving screen...")
+			lgr := lgr.With(slog.Int("attempt", attempt))
+			attempt++
+
+			lgr.Info("Resolving screen...")
 
 			err := resolve(ctx, screen)
 
@@ -78,17 +78,17 @@ func (s *Screens) run(ctx context.Context, screen Screen) {
 
 			if err != nil {
 				if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
-					infoLogger(ctx).Printf("Screen failed: %v\n", err)
+					lgr.Info("Screen failed", slog.Any("error", err))
 
 					continue
 				}
 
-				errorLogger(ctx).Printf("Failed to run in chrome: %v\n", err)
+				lgr.Error("Failed to run in chrome", slog.Any("error", err))
 
 				continue
 			}
 
-			infoLogger(ctx).Println("Screen passed")
+			lgr.Info("Screen passed")
 		}
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"image/jpeg"
+	"log/slog"
 
 	"github.com/chromedp/chromedp"
 )
@@ -20,12 +21,12 @@ func (c *chromeLogin) wrapWithScreenshot(ctx context.Context, rootErr error) err
 	var imageData []byte
 
 	if err := chromedp.Run(ctx, chromedp.FullScreenshot(&imageData, jpeg.DefaultQuality)); err != nil {
-		errorLogger(ctx).Printf("Failed to take screenshot: %v\n", err)
+		logger(ctx).Error("Failed to take screenshot", slog.Any("error", err))
 
 		return rootErr
 	}
 
-	infoLogger(ctx).Println("Screenshot taken")
+	logger(ctx).Info("Screenshot taken")
 
 	return &WithScreenshotError{
 		Err:        rootErr,
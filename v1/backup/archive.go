@@ -0,0 +1,295 @@
+package backup
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Format is the format of a backup archive.
+type Format int
+
+const (
+	// FormatZip is a zip archive.
+	FormatZip Format = iota
+	// FormatTarGz is a gzip-compressed tar archive.
+	FormatTarGz
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatZip:
+		return "zip"
+	case FormatTarGz:
+		return "tar.gz"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+var errUnknownFormat = errors.New("unknown archive format")
+
+// FormatFromName returns the format matching the extension of the file name: ".zip", ".tar.gz" or ".tgz".
+func FormatFromName(name string) (Format, error) {
+	name = strings.ToLower(name)
+
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return FormatZip, nil
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return FormatTarGz, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", errUnknownFormat, name)
+	}
+}
+
+// archiveWriter writes the files of an archive, one after the other.
+type archiveWriter interface {
+	add(name string, modTime time.Time, size int64, content io.Reader) error
+	Close() error
+}
+
+func newArchiveWriter(writer io.Writer, format Format) (archiveWriter, error) { //nolint:ireturn
+	switch format {
+	case FormatZip:
+		return &zipWriter{writer: zip.NewWriter(writer)}, nil
+	case FormatTarGz:
+		compressed := gzip.NewWriter(writer)
+
+		return &tarWriter{compressed: compressed, writer: tar.NewWriter(compressed)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %v", errUnknownFormat, format)
+	}
+}
+
+type zipWriter struct {
+	writer *zip.Writer
+}
+
+func (w *zipWriter) add(name string, modTime time.Time, _ int64, content io.Reader) error {
+	header := new(zip.FileHeader)
+	header.Name = name
+	header.Method = zip.Deflate
+	header.Modified = modTime
+
+	file, err := w.writer.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("create %q: %w", name, err)
+	}
+
+	if _, err := io.Copy(file, content); err != nil {
+		return fmt.Errorf("write %q: %w", name, err)
+	}
+
+	return nil
+}
+
+func (w *zipWriter) Close() error {
+	if err := w.writer.Close(); err != nil {
+		return fmt.Errorf("close zip: %w", err)
+	}
+
+	return nil
+}
+
+type tarWriter struct {
+	compressed *gzip.Writer
+	writer     *tar.Writer
+}
+
+func (w *tarWriter) add(name string, modTime time.Time, size int64, content io.Reader) error {
+	header := new(tar.Header)
+	header.Typeflag = tar.TypeReg
+	header.Name = name
+	header.Mode = 0o600
+	header.Size = size
+	header.ModTime = modTime
+
+	if err := w.writer.WriteHeader(header); err != nil {
+		return fmt.Errorf("create %q: %w", name, err)
+	}
+
+	if _, err := io.Copy(w.writer, content); err != nil {
+		return fmt.Errorf("write %q: %w", name, err)
+	}
+
+	return nil
+}
+
+func (w *tarWriter) Close() error {
+	if err := w.writer.Close(); err != nil {
+		return fmt.Errorf("close tar: %w", err)
+	}
+
+	if err := w.compressed.Close(); err != nil {
+		return fmt.Errorf("close gzip: %w", err)
+	}
+
+	return nil
+}
+
+var (
+	errNoManifest = errors.New("the archive has no manifest")
+	errStopWalk   = errors.New("stop walk")
+)
+
+// Reader reads a backup archive written by Write.
+type Reader struct {
+	// Manifest is the manifest of the archive.
+	Manifest *Manifest
+
+	format Format
+	source io.ReaderAt
+	size   int64
+	closer io.Closer
+}
+
+// OpenReader opens the backup archive with the given file name.
+// The format is guessed from the extension of the name, see FormatFromName.
+func OpenReader(name string) (*Reader, error) {
+	format, err := FormatFromName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+
+		return nil, fmt.Errorf("stat archive: %w", err)
+	}
+
+	reader, err := NewReader(file, info.Size(), format)
+	if err != nil {
+		_ = file.Close()
+
+		return nil, err
+	}
+
+	reader.closer = file
+
+	return reader, nil
+}
+
+// NewReader reads a backup archive of the given size and format, and decodes its manifest.
+func NewReader(source io.ReaderAt, size int64, format Format) (*Reader, error) {
+	reader := &Reader{
+		Manifest: nil,
+		format:   format,
+		source:   source,
+		size:     size,
+		closer:   nil,
+	}
+
+	err := reader.walk(func(name string, content io.Reader) error {
+		if name != ManifestName {
+			return nil
+		}
+
+		manifest, err := ReadManifest(content)
+		if err != nil {
+			return err
+		}
+
+		reader.Manifest = manifest
+
+		return errStopWalk
+	})
+
+	switch {
+	case errors.Is(err, errStopWalk):
+		return reader, nil
+	case err != nil:
+		return nil, err
+	default:
+		return nil, errNoManifest
+	}
+}
+
+// Close closes the file opened by OpenReader.
+func (r *Reader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+
+	if err := r.closer.Close(); err != nil {
+		return fmt.Errorf("close archive: %w", err)
+	}
+
+	return nil
+}
+
+// walk calls the function for each file of the archive, in order, until it returns an error.
+func (r *Reader) walk(walkFn func(name string, content io.Reader) error) error {
+	switch r.format {
+	case FormatZip:
+		return r.walkZip(walkFn)
+	case FormatTarGz:
+		return r.walkTarGz(walkFn)
+	default:
+		return fmt.Errorf("%w: %v", errUnknownFormat, r.format)
+	}
+}
+
+func (r *Reader) walkZip(walkFn func(name string, content io.Reader) error) error {
+	archive, err := zip.NewReader(r.source, r.size)
+	if err != nil {
+		return fmt.Errorf("read zip: %w", err)
+	}
+
+	for _, file := range archive.File {
+		content, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("open %q: %w", file.Name, err)
+		}
+
+		err = walkFn(file.Name, content)
+
+		_ = content.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Reader) walkTarGz(walkFn func(name string, content io.Reader) error) error {
+	compressed, err := gzip.NewReader(io.NewSectionReader(r.source, 0, r.size))
+	if err != nil {
+		return fmt.Errorf("read gzip: %w", err)
+	}
+
+	archive := tar.NewReader(compressed)
+
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("read tar: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := walkFn(header.Name, archive); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,511 @@
+// Package backup writes a whole Digiposte account to a zip or tar.gz archive.
+//
+// The archive starts with a JSON manifest, named ManifestName, describing the folders, the documents,
+// the trash and the shares. It is followed by the content of each document, under "files/" for the
+// documents of the safe and under "trash/" for the trashed ones.
+// The manifest is enough to audit a backup offline, and to compare it with a later one using Manifest.Diff.
+//
+// An incremental backup, written with the Incremental option, only archives the content of the documents
+// added or changed since a previous manifest. Its manifest still describes the whole account, records
+// the changes, and tells for each document which archive holds its content with Document.ArchivedAt.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+// Archive directories of the content of the documents.
+const (
+	filesDir = "files"
+	trashDir = "trash"
+)
+
+// Option configures a backup.
+type Option func(o *options)
+
+type options struct {
+	trash    bool
+	workers  int
+	progress func(digiposte.DownloadEvent)
+	tempDir  string
+	previous *Manifest
+}
+
+// WithoutTrash leaves the trashed folders and documents out of the backup.
+func WithoutTrash() Option {
+	return func(o *options) {
+		o.trash = false
+	}
+}
+
+// WithWorkers sets the number of documents downloaded at the same time.
+// The default is digiposte.DefaultDownloadWorkers.
+func WithWorkers(workers int) Option {
+	return func(o *options) {
+		o.workers = workers
+	}
+}
+
+// WithProgress sets a function receiving the progress of the download of the documents.
+func WithProgress(progress func(digiposte.DownloadEvent)) Option {
+	return func(o *options) {
+		o.progress = progress
+	}
+}
+
+// WithTempDir sets the directory the documents are downloaded to before being archived.
+// The default is os.TempDir.
+func WithTempDir(dir string) Option {
+	return func(o *options) {
+		o.tempDir = dir
+	}
+}
+
+// Incremental only archives the documents added or changed since the previous manifest,
+// the manifest of the last backup, full or incremental.
+// A document is changed if its size or its creation date changed: Digiposte does not edit the content.
+func Incremental(previous *Manifest) Option {
+	return func(o *options) {
+		o.previous = previous
+	}
+}
+
+// Write writes a backup of the whole account to the writer, and returns its manifest.
+//
+// The documents are first downloaded concurrently to a temporary directory, then archived,
+// so the temporary directory needs as much free space as the safe uses.
+// If an error is returned, the archive is incomplete.
+func Write(
+	ctx context.Context,
+	client *digiposte.Client,
+	writer io.Writer,
+	format Format,
+	opts ...Option,
+) (*Manifest, error) {
+	config := &options{
+		trash:    true,
+		workers:  digiposte.DefaultDownloadWorkers,
+		progress: func(digiposte.DownloadEvent) {},
+		tempDir:  "",
+		previous: nil,
+	}
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	archive, err := newArchiveWriter(writer, format)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := newBuilder(client)
+
+	if err := builder.collect(ctx, config.trash); err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]struct{})
+
+	if config.previous != nil {
+		builder.reuse(config.previous, files)
+	}
+
+	staging, err := os.MkdirTemp(config.tempDir, "digiposte-backup-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temporary directory: %w", err)
+	}
+
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	if err := builder.download(ctx, staging, config); err != nil {
+		return nil, err
+	}
+
+	if err := builder.checksum(staging, files); err != nil {
+		return nil, err
+	}
+
+	if config.previous != nil {
+		builder.manifest.Previous = &config.previous.CreatedAt
+		builder.manifest.Changes = config.previous.Diff(builder.manifest)
+	}
+
+	if err := builder.archive(archive, staging); err != nil {
+		_ = archive.Close()
+
+		return nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return builder.manifest, nil
+}
+
+// builder collects the content of the account into a manifest.
+type builder struct {
+	client   *digiposte.Client
+	manifest *Manifest
+
+	folders   map[digiposte.FolderID]*Folder
+	documents map[digiposte.DocumentID]*Document
+	sources   []*digiposte.Document
+}
+
+func newBuilder(client *digiposte.Client) *builder {
+	return &builder{
+		client: client,
+		manifest: &Manifest{
+			Version:   manifestVersion,
+			CreatedAt: time.Now().UTC(),
+			Previous:  nil,
+			Folders:   []*Folder{},
+			Documents: []*Document{},
+			Shares:    []*Share{},
+			Changes:   nil,
+		},
+		folders:   make(map[digiposte.FolderID]*Folder),
+		documents: make(map[digiposte.DocumentID]*Document),
+		sources:   nil,
+	}
+}
+
+// collect fills the manifest with the folders, the documents and the shares.
+func (b *builder) collect(ctx context.Context, trash bool) error {
+	if err := b.collectSafe(ctx); err != nil {
+		return err
+	}
+
+	if trash {
+		if err := b.collectTrash(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := b.collectShares(ctx); err != nil {
+		return err
+	}
+
+	b.manifest.sort()
+
+	return nil
+}
+
+// collectSafe walks the folders and the documents, from the root.
+func (b *builder) collectSafe(ctx context.Context) error {
+	folderIDs := map[string]digiposte.FolderID{".": digiposte.RootFolderID}
+
+	err := b.client.Walk(ctx, digiposte.RootFolderID, func(entryPath string, entry *digiposte.PathEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		parentID := folderIDs[path.Dir(entryPath)]
+
+		switch {
+		case entryPath == ".":
+		case entry.IsFolder():
+			folderIDs[entryPath] = entry.Folder.InternalID
+
+			b.addFolder(entry.Folder, parentID, entryPath, false)
+		default:
+			b.addDocument(entry.Document, folderPath(path.Dir(entryPath)), false)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("walk: %w", err)
+	}
+
+	return nil
+}
+
+// collectTrash lists the trashed folders and documents.
+func (b *builder) collectTrash(ctx context.Context) error {
+	folders, err := b.client.GetTrashedFolders(ctx)
+	if err != nil {
+		return fmt.Errorf("get trashed folders: %w", err)
+	}
+
+	for _, folder := range folders.Folders {
+		b.addFolderTree(folder, folder.ParentID, digiposte.SanitizeName(folder.Name))
+	}
+
+	documents, err := b.client.TrashedDocumentsIter(ctx).All()
+	if err != nil {
+		return fmt.Errorf("get trashed documents: %w", err)
+	}
+
+	for _, document := range documents {
+		var dir string
+
+		if folder, ok := b.folders[digiposte.FolderID(document.FolderID)]; ok {
+			dir = folder.Path
+		}
+
+		b.addDocument(document, dir, true)
+	}
+
+	return nil
+}
+
+func (b *builder) addFolderTree(folder *digiposte.Folder, parentID digiposte.FolderID, folderPath string) {
+	b.addFolder(folder, parentID, folderPath, true)
+
+	for _, sub := range folder.Folders {
+		b.addFolderTree(sub, folder.InternalID, path.Join(folderPath, digiposte.SanitizeName(sub.Name)))
+	}
+}
+
+// collectShares records the shares, and the shares of each document.
+func (b *builder) collectShares(ctx context.Context) error {
+	result, err := b.client.ListSharesWithDocuments(ctx)
+	if err != nil {
+		return fmt.Errorf("list shares: %w", err)
+	}
+
+	for _, item := range result.ShareDataAndDocuments {
+		share := &Share{
+			ID:             item.ShareData.InternalID,
+			Title:          item.ShareData.Title,
+			ShortURL:       item.ShareData.ShortURL,
+			StartDate:      item.ShareData.StartDate,
+			EndDate:        item.ShareData.EndDate,
+			RecipientMails: append([]string{}, item.ShareData.RecipientMails...),
+			DocumentIDs:    make([]digiposte.DocumentID, 0, len(item.Documents)),
+		}
+
+		for _, document := range item.Documents {
+			share.DocumentIDs = append(share.DocumentIDs, document.InternalID)
+
+			if backedUp, ok := b.documents[document.InternalID]; ok {
+				backedUp.Shares = append(backedUp.Shares, share.ID)
+			}
+		}
+
+		b.manifest.Shares = append(b.manifest.Shares, share)
+	}
+
+	return nil
+}
+
+func (b *builder) addFolder(folder *digiposte.Folder, parentID digiposte.FolderID, folderPath string, trashed bool) {
+	backedUp := &Folder{
+		ID:        folder.InternalID,
+		ParentID:  parentID,
+		Path:      folderPath,
+		Name:      folder.Name,
+		CreatedAt: folder.CreatedAt,
+		UpdatedAt: folder.UpdatedAt,
+		Trashed:   trashed,
+	}
+
+	b.folders[folder.InternalID] = backedUp
+	b.manifest.Folders = append(b.manifest.Folders, backedUp)
+}
+
+func (b *builder) addDocument(document *digiposte.Document, folderPath string, trashed bool) {
+	backedUp := &Document{
+		ID:          document.InternalID,
+		Name:        document.Name,
+		FolderID:    digiposte.FolderID(document.FolderID),
+		FolderPath:  folderPath,
+		Location:    document.Location,
+		Trashed:     trashed,
+		CreatedAt:   document.CreatedAt,
+		Size:        document.Size,
+		ContentSize: 0,
+		MimeType:    document.MimeType,
+		SHA256:      "",
+		Tags:        append([]string{}, document.UserTags...),
+		Health:      document.HealthDocument,
+		Read:        document.Read,
+		Favorite:    document.Favorite,
+		Certified:   document.Certified,
+		Shares:      []digiposte.ShareID{},
+		File:        "",
+		ArchivedAt:  time.Time{},
+	}
+
+	b.documents[document.InternalID] = backedUp
+	b.manifest.Documents = append(b.manifest.Documents, backedUp)
+	b.sources = append(b.sources, document)
+}
+
+// download downloads the content of the documents to the staging directory, named after their ID.
+func (b *builder) download(ctx context.Context, staging string, config *options) error {
+	downloader := b.client.NewDownloader(staging,
+		digiposte.WithDownloadWorkers(config.workers),
+		digiposte.WithDownloadProgress(config.progress),
+		digiposte.WithDocumentPath(func(document *digiposte.Document) string {
+			return string(document.InternalID)
+		}),
+	)
+
+	if _, err := downloader.Download(ctx, digiposte.DocumentsOf(b.sources...)); err != nil {
+		return fmt.Errorf("download documents: %w", err)
+	}
+
+	return nil
+}
+
+// reuse keeps the content of the documents unchanged since the previous manifest in its archive,
+// and leaves them out of the download. Their archive file names are added to the files.
+func (b *builder) reuse(previous *Manifest, files map[string]struct{}) {
+	before := make(map[digiposte.DocumentID]*Document, len(previous.Documents))
+	for _, document := range previous.Documents {
+		before[document.ID] = document
+	}
+
+	sources := b.sources[:0]
+
+	for _, source := range b.sources {
+		document := b.documents[source.InternalID]
+
+		reused, ok := before[document.ID]
+		if !ok || reused.File == "" || reused.Size != source.Size || !reused.CreatedAt.Equal(source.CreatedAt) {
+			sources = append(sources, source)
+
+			continue
+		}
+
+		document.ContentSize = reused.ContentSize
+		document.SHA256 = reused.SHA256
+		document.File = reused.File
+		document.ArchivedAt = reused.ArchivedAt
+		files[document.File] = struct{}{}
+	}
+
+	b.sources = sources
+}
+
+// checksum sets the content size, the checksum and the archive file name of the downloaded documents,
+// unique among the files.
+func (b *builder) checksum(staging string, files map[string]struct{}) error {
+	for _, document := range b.manifest.Documents {
+		if document.File != "" {
+			continue
+		}
+
+		size, sum, err := fileChecksum(filepath.Join(staging, string(document.ID)))
+		if err != nil {
+			return err
+		}
+
+		document.ContentSize = size
+		document.SHA256 = sum
+		document.File = uniqueFile(files, document)
+		document.ArchivedAt = b.manifest.CreatedAt
+	}
+
+	return nil
+}
+
+func fileChecksum(name string) (int64, string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return 0, "", fmt.Errorf("open: %w", err)
+	}
+
+	defer file.Close()
+
+	hash := sha256.New()
+
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return 0, "", fmt.Errorf("read %q: %w", name, err)
+	}
+
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// archive writes the manifest, then the content of the documents downloaded by this backup.
+func (b *builder) archive(archive archiveWriter, staging string) error {
+	manifest, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+
+	err = archive.add(ManifestName, b.manifest.CreatedAt, int64(len(manifest)), bytes.NewReader(manifest))
+	if err != nil {
+		return err
+	}
+
+	for _, document := range b.manifest.Documents {
+		if !b.manifest.Archived(document) {
+			continue
+		}
+
+		if err := addFile(archive, document, filepath.Join(staging, string(document.ID))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addFile(archive archiveWriter, document *Document, name string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+
+	defer file.Close()
+
+	return archive.add(document.File, document.CreatedAt, document.ContentSize, file)
+}
+
+// uniqueFile returns the archive file name of the document, unique among the given files.
+func uniqueFile(files map[string]struct{}, document *Document) string {
+	dir := filesDir
+	if document.Trashed {
+		dir = trashDir
+	}
+
+	segments := []string{dir}
+
+	if document.FolderPath != "" {
+		for _, segment := range strings.Split(document.FolderPath, "/") {
+			segments = append(segments, digiposte.SanitizeName(segment))
+		}
+	}
+
+	name := path.Join(append(segments, digiposte.SanitizeName(document.Name))...)
+
+	if _, ok := files[name]; ok {
+		ext := path.Ext(name)
+		if ext == path.Base(name) {
+			ext = ""
+		}
+
+		name = strings.TrimSuffix(name, ext) + "~" + string(document.ID) + ext
+	}
+
+	files[name] = struct{}{}
+
+	return name
+}
+
+func folderPath(dir string) string {
+	if dir == "." {
+		return ""
+	}
+
+	return dir
+}
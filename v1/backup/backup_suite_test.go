@@ -0,0 +1,15 @@
+package backup_test
+
+import (
+	"testing"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+func TestBackup(t *testing.T) {
+	t.Parallel()
+
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "Backup Suite")
+}
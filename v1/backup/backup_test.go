@@ -0,0 +1,308 @@
+package backup_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+	"github.com/holyhope/digiposte-go-sdk/v1/backup"
+	"github.com/holyhope/digiposte-go-sdk/v1/digipostetest"
+)
+
+// readArchive returns the files of an archive, in order.
+func readArchive(content []byte, format backup.Format) ([]string, map[string]string) {
+	var names []string
+
+	files := make(map[string]string)
+
+	switch format {
+	case backup.FormatZip:
+		reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		for _, file := range reader.File {
+			opened, err := file.Open()
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			data, err := io.ReadAll(opened)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			names = append(names, file.Name)
+			files[file.Name] = string(data)
+		}
+	case backup.FormatTarGz:
+		compressed, err := gzip.NewReader(bytes.NewReader(content))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		reader := tar.NewReader(compressed)
+
+		for {
+			header, err := reader.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			data, err := io.ReadAll(reader)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			names = append(names, header.Name)
+			files[header.Name] = string(data)
+		}
+	}
+
+	return names, files
+}
+
+var _ = ginkgo.Describe("Write", func() {
+	var (
+		server  *digipostetest.Server
+		client  *digiposte.Client
+		folder  *digiposte.Folder
+		avis    *digiposte.Document
+		health  *digiposte.Document
+		trashed *digiposte.Document
+		share   *digiposte.Share
+	)
+
+	ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+		server = digipostetest.NewServer()
+		ginkgo.DeferCleanup(server.Close)
+
+		var err error
+
+		client, err = digiposte.NewAuthenticatedClient(ctx, new(http.Client), server.Config())
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		folder = server.AddFolder(digiposte.RootFolderID, "Impôts")
+		sub := server.AddFolder(folder.InternalID, "2024")
+
+		avis = server.AddDocument(sub.InternalID, "avis.pdf", []byte("the avis"), digiposte.LocationSafe)
+		trashed = server.AddDocument(digiposte.RootFolderID, "old.txt", []byte("old"), digiposte.LocationSafe)
+
+		health, err = client.CreateDocument(ctx, digiposte.RootFolderID, "ordonnance.txt",
+			strings.NewReader("ordonnance"), digiposte.DocumentTypeHealth)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		gomega.Expect(client.MultiTag(ctx, map[digiposte.DocumentID][]digiposte.DocumentTag{
+			avis.InternalID: {"taxes"},
+		})).To(gomega.Succeed())
+		gomega.Expect(client.Trash(ctx, []digiposte.DocumentID{trashed.InternalID}, nil)).To(gomega.Succeed())
+
+		start := time.Now().Add(-time.Minute)
+
+		share, err = client.CreateShare(ctx, start, start.Add(time.Hour), "taxes", "1234")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(client.SetShareDocuments(ctx, share.InternalID, []digiposte.DocumentID{avis.InternalID})).
+			To(gomega.Succeed())
+	})
+
+	writeBackup := func(ctx ginkgo.SpecContext, format backup.Format, options ...backup.Option) (
+		*backup.Manifest,
+		[]string,
+		map[string]string,
+	) {
+		buffer := new(bytes.Buffer)
+
+		manifest, err := backup.Write(ctx, client, buffer, format, options...)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		names, files := readArchive(buffer.Bytes(), format)
+
+		return manifest, names, files
+	}
+
+	ginkgo.DescribeTable("Should archive the documents after the manifest",
+		func(ctx ginkgo.SpecContext, format backup.Format) {
+			manifest, names, files := writeBackup(ctx, format)
+
+			gomega.Expect(names).To(gomega.Equal([]string{
+				backup.ManifestName,
+				"files/ordonnance.txt",
+				"files/Impôts/2024/avis.pdf",
+				"trash/old.txt",
+			}))
+			gomega.Expect(files).To(gomega.HaveKeyWithValue("files/Impôts/2024/avis.pdf", "the avis"))
+
+			written, err := backup.ReadManifest(strings.NewReader(files[backup.ManifestName]))
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(written.Documents).To(gomega.HaveLen(len(manifest.Documents)))
+			gomega.Expect(written.Diff(manifest)).To(gomega.BeEmpty())
+		},
+		ginkgo.Entry("zip", backup.FormatZip),
+		ginkgo.Entry("tar.gz", backup.FormatTarGz),
+	)
+
+	ginkgo.It("Should record the metadata in the manifest", func(ctx ginkgo.SpecContext) {
+		manifest, _, _ := writeBackup(ctx, backup.FormatZip)
+
+		gomega.Expect(manifest.Folders).To(gomega.HaveLen(2))
+		gomega.Expect(manifest.Folders[1].Path).To(gomega.Equal("Impôts/2024"))
+		gomega.Expect(manifest.Folders[1].ParentID).To(gomega.Equal(folder.InternalID))
+
+		gomega.Expect(manifest.Documents).To(gomega.HaveLen(3))
+
+		backedUpHealth, backedUpAvis, backedUpTrashed := manifest.Documents[0], manifest.Documents[1], manifest.Documents[2]
+
+		gomega.Expect(backedUpHealth.ID).To(gomega.Equal(health.InternalID))
+		gomega.Expect(backedUpHealth.Health).To(gomega.BeTrue())
+
+		gomega.Expect(backedUpAvis.ID).To(gomega.Equal(avis.InternalID))
+		gomega.Expect(backedUpAvis.FolderPath).To(gomega.Equal("Impôts/2024"))
+		gomega.Expect(backedUpAvis.Tags).To(gomega.Equal([]string{"taxes"}))
+		gomega.Expect(backedUpAvis.Shares).To(gomega.Equal([]digiposte.ShareID{share.InternalID}))
+		gomega.Expect(backedUpAvis.SHA256).To(gomega.HaveLen(64))
+
+		gomega.Expect(backedUpTrashed.ID).To(gomega.Equal(trashed.InternalID))
+		gomega.Expect(backedUpTrashed.Trashed).To(gomega.BeTrue())
+
+		gomega.Expect(manifest.Shares).To(gomega.HaveLen(1))
+		gomega.Expect(manifest.Shares[0].DocumentIDs).To(gomega.Equal([]digiposte.DocumentID{avis.InternalID}))
+	})
+
+	ginkgo.It("Should leave the trash out", func(ctx ginkgo.SpecContext) {
+		_, names, _ := writeBackup(ctx, backup.FormatTarGz, backup.WithoutTrash())
+		gomega.Expect(names).ToNot(gomega.ContainElement("trash/old.txt"))
+	})
+
+	ginkgo.It("Should record the parent of the trashed folders", func(ctx ginkgo.SpecContext) {
+		old := server.AddFolder(folder.InternalID, "2019")
+		gomega.Expect(client.Trash(ctx, nil, []digiposte.FolderID{old.InternalID})).To(gomega.Succeed())
+
+		manifest, _, _ := writeBackup(ctx, backup.FormatZip)
+
+		gomega.Expect(manifest.Folders).To(gomega.ContainElement(gomega.And(
+			gomega.HaveField("ID", old.InternalID),
+			gomega.HaveField("ParentID", folder.InternalID),
+			gomega.HaveField("Trashed", true),
+		)))
+	})
+
+	ginkgo.It("Should compare two backups", func(ctx ginkgo.SpecContext) {
+		before, _, _ := writeBackup(ctx, backup.FormatZip)
+
+		gomega.Expect(client.MultiTag(ctx, map[digiposte.DocumentID][]digiposte.DocumentTag{
+			avis.InternalID: {"2024"},
+		})).To(gomega.Succeed())
+		gomega.Expect(client.Move(ctx, folder.InternalID, []digiposte.DocumentID{health.InternalID}, nil)).
+			To(gomega.Succeed())
+		gomega.Expect(client.Delete(ctx, []digiposte.DocumentID{trashed.InternalID}, nil)).To(gomega.Succeed())
+
+		after, _, _ := writeBackup(ctx, backup.FormatZip)
+
+		kinds := make(map[digiposte.DocumentID][]backup.ChangeKind)
+
+		for _, change := range before.Diff(after) {
+			document := change.After
+			if document == nil {
+				document = change.Before
+			}
+
+			kinds[document.ID] = append(kinds[document.ID], change.Kind)
+		}
+
+		gomega.Expect(kinds).To(gomega.Equal(map[digiposte.DocumentID][]backup.ChangeKind{
+			avis.InternalID:    {backup.ChangeRetagged},
+			health.InternalID:  {backup.ChangeMoved},
+			trashed.InternalID: {backup.ChangeRemoved},
+		}))
+	})
+
+	ginkgo.It("Should only archive the changed documents incrementally", func(ctx ginkgo.SpecContext) {
+		previous, _, _ := writeBackup(ctx, backup.FormatZip)
+
+		added := server.AddDocument(folder.InternalID, "new.txt", []byte("new"), digiposte.LocationSafe)
+		gomega.Expect(client.Move(ctx, folder.InternalID, []digiposte.DocumentID{health.InternalID}, nil)).
+			To(gomega.Succeed())
+		gomega.Expect(client.Delete(ctx, []digiposte.DocumentID{trashed.InternalID}, nil)).To(gomega.Succeed())
+
+		manifest, names, files := writeBackup(ctx, backup.FormatTarGz, backup.Incremental(previous))
+
+		gomega.Expect(names).To(gomega.Equal([]string{backup.ManifestName, "files/Impôts/new.txt"}))
+		gomega.Expect(manifest.Previous).To(gomega.HaveValue(gomega.BeTemporally("==", previous.CreatedAt)))
+		gomega.Expect(manifest.Documents).To(gomega.HaveLen(3))
+
+		for _, document := range manifest.Documents {
+			gomega.Expect(manifest.Archived(document)).To(gomega.Equal(document.ID == added.InternalID), document.Name)
+		}
+
+		written, err := backup.ReadManifest(strings.NewReader(files[backup.ManifestName]))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		kinds := make(map[digiposte.DocumentID]backup.ChangeKind)
+
+		for _, change := range written.Changes {
+			document := change.After
+			if document == nil {
+				document = change.Before
+			}
+
+			kinds[document.ID] = change.Kind
+		}
+
+		gomega.Expect(kinds).To(gomega.Equal(map[digiposte.DocumentID]backup.ChangeKind{
+			added.InternalID:   backup.ChangeAdded,
+			health.InternalID:  backup.ChangeMoved,
+			trashed.InternalID: backup.ChangeRemoved,
+		}))
+
+		unchanged, _, _ := writeBackup(ctx, backup.FormatZip, backup.Incremental(written))
+		gomega.Expect(unchanged.Changes).To(gomega.BeEmpty())
+		gomega.Expect(unchanged.Documents).To(gomega.ContainElement(gomega.SatisfyAll(
+			gomega.HaveField("ID", added.InternalID),
+			gomega.HaveField("ArchivedAt", gomega.BeTemporally("==", written.CreatedAt)),
+		)))
+	})
+
+	ginkgo.It("Should compare the reported sizes incrementally", func(ctx ginkgo.SpecContext) {
+		previous, _, _ := writeBackup(ctx, backup.FormatZip)
+
+		// The downloaded content may not have the size reported by Digiposte.
+		for _, document := range previous.Documents {
+			gomega.Expect(document.ContentSize).To(gomega.Equal(document.Size))
+
+			document.ContentSize++
+		}
+
+		manifest, names, _ := writeBackup(ctx, backup.FormatZip, backup.Incremental(previous))
+		gomega.Expect(names).To(gomega.Equal([]string{backup.ManifestName}))
+
+		for i, document := range manifest.Documents {
+			gomega.Expect(document.Size).To(gomega.Equal(previous.Documents[i].Size))
+			gomega.Expect(document.ContentSize).To(gomega.Equal(previous.Documents[i].ContentSize))
+		}
+	})
+
+	ginkgo.It("Should read the manifests of version 1", func() {
+		manifest, err := backup.ReadManifest(strings.NewReader(`{
+			"version": 1,
+			"created_at": "2024-01-02T03:04:05Z",
+			"documents": [{"id": "avis", "file": "files/avis.pdf", "size": 8}]
+		}`))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(manifest.Archived(manifest.Documents[0])).To(gomega.BeTrue())
+		gomega.Expect(manifest.Documents[0].ContentSize).To(gomega.BeEquivalentTo(8))
+
+		_, err = backup.ReadManifest(strings.NewReader(`{"version": 99}`))
+		gomega.Expect(err).To(gomega.HaveOccurred())
+	})
+
+	ginkgo.It("Should guess the format from the file name", func() {
+		gomega.Expect(backup.FormatFromName("backup.TGZ")).To(gomega.Equal(backup.FormatTarGz))
+		gomega.Expect(backup.FormatFromName("backup.zip")).To(gomega.Equal(backup.FormatZip))
+
+		_, err := backup.FormatFromName("backup.rar")
+		gomega.Expect(err).To(gomega.HaveOccurred())
+	})
+})
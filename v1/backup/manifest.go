@@ -0,0 +1,284 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/holyhope/digiposte-go-sdk/internal/utils"
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+// ManifestName is the name of the manifest in the archive. It is the first file of the archive.
+const ManifestName = "manifest.json"
+
+// Versions of the format of the manifest.
+// Version 2 added the incremental backups: Manifest.Previous, Manifest.Changes and Document.ArchivedAt,
+// and Document.ContentSize, Document.Size being the size reported by Digiposte.
+const (
+	manifestVersion1 = 1
+	manifestVersion  = 2
+)
+
+var (
+	errManifestVersion = errors.New("unsupported manifest version")
+	errChangeKind      = errors.New("unknown change kind")
+)
+
+// Manifest describes the content of a backup archive. It is a snapshot of the account.
+// Its lists are sorted, so that two manifests can be compared with a text diff.
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// Previous is the creation time of the manifest an incremental backup is based on, nil for a full backup.
+	Previous  *time.Time  `json:"previous,omitempty"`
+	Folders   []*Folder   `json:"folders"`
+	Documents []*Document `json:"documents"`
+	Shares    []*Share    `json:"shares"`
+	// Changes are the changes of the documents since the previous manifest, for an incremental backup.
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// Folder is a folder of the backup.
+type Folder struct {
+	ID digiposte.FolderID `json:"id"`
+	// ParentID is the ID of the parent folder, or RootFolderID for the top-most folders.
+	ParentID digiposte.FolderID `json:"parent_id"`
+	// Path is the slash-separated path of the folder, from the root or from the trash.
+	// Its names are escaped with digiposte.SanitizeName.
+	Path      string    `json:"path"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Trashed   bool      `json:"trashed"`
+}
+
+// Document is a document of the backup.
+type Document struct {
+	ID       digiposte.DocumentID `json:"id"`
+	Name     string               `json:"name"`
+	FolderID digiposte.FolderID   `json:"folder_id"`
+	// FolderPath is the path of the folder of the document, empty for the root or an unknown folder.
+	FolderPath string `json:"folder_path"`
+	// Location is the location of the document, such as "SAFE" or "TRASH_INBOX".
+	Location  string    `json:"location"`
+	Trashed   bool      `json:"trashed"`
+	CreatedAt time.Time `json:"created_at"`
+	// Size is the size reported by Digiposte.
+	Size int64 `json:"size"`
+	// ContentSize is the size of the content in the archive.
+	ContentSize int64  `json:"content_size"`
+	MimeType    string `json:"mime_type"`
+	// SHA256 is the hexadecimal SHA-256 checksum of the content.
+	SHA256    string              `json:"sha256"`
+	Tags      []string            `json:"tags"`
+	Health    bool                `json:"health"`
+	Read      bool                `json:"read"`
+	Favorite  bool                `json:"favorite"`
+	Certified bool                `json:"certified"`
+	Shares    []digiposte.ShareID `json:"shares"`
+	// File is the name of the content in the archive.
+	File string `json:"file"`
+	// ArchivedAt is the creation time of the manifest of the archive holding the content.
+	// It is older than the manifest for the documents left unchanged by an incremental backup.
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// Share is a share of the backup.
+type Share struct {
+	ID             digiposte.ShareID      `json:"id"`
+	Title          string                 `json:"title"`
+	ShortURL       string                 `json:"short_url"`
+	StartDate      time.Time              `json:"start_date"`
+	EndDate        time.Time              `json:"end_date"`
+	RecipientMails []string               `json:"recipient_mails"`
+	DocumentIDs    []digiposte.DocumentID `json:"document_ids"`
+}
+
+// ReadManifest decodes a manifest, such as the manifest.json file of an archive.
+// The manifests of the older versions are upgraded to the current one.
+func ReadManifest(reader io.Reader) (*Manifest, error) {
+	manifest := new(Manifest)
+
+	if err := json.NewDecoder(reader).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("decode manifest: %w", err)
+	}
+
+	switch manifest.Version {
+	case manifestVersion1:
+		manifest.upgradeV1()
+	case manifestVersion:
+	default:
+		return nil, fmt.Errorf("%w: %d", errManifestVersion, manifest.Version)
+	}
+
+	return manifest, nil
+}
+
+// upgradeV1 upgrades a manifest of version 1, always written by a full backup,
+// whose sizes are the sizes of the content.
+func (m *Manifest) upgradeV1() {
+	m.Version = manifestVersion
+
+	for _, document := range m.Documents {
+		document.ArchivedAt = m.CreatedAt
+		document.ContentSize = document.Size
+	}
+}
+
+// Archived reports whether the content of the document is in the archive of the manifest.
+func (m *Manifest) Archived(document *Document) bool {
+	return document.ArchivedAt.Equal(m.CreatedAt)
+}
+
+// sort sorts the lists of the manifest: the folders and documents by trash flag then path, the shares by ID.
+func (m *Manifest) sort() {
+	sort.Slice(m.Folders, func(i, j int) bool {
+		a, b := m.Folders[i], m.Folders[j]
+		if a.Trashed != b.Trashed {
+			return b.Trashed
+		}
+
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+
+		return a.ID < b.ID
+	})
+
+	sort.Slice(m.Documents, func(i, j int) bool {
+		a, b := m.Documents[i], m.Documents[j]
+		if a.Trashed != b.Trashed {
+			return b.Trashed
+		}
+
+		if a.FolderPath != b.FolderPath {
+			return a.FolderPath < b.FolderPath
+		}
+
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+
+		return a.ID < b.ID
+	})
+
+	sort.Slice(m.Shares, func(i, j int) bool {
+		return m.Shares[i].ID < m.Shares[j].ID
+	})
+}
+
+// ChangeKind is the kind of a Change between two manifests.
+type ChangeKind int
+
+const (
+	// ChangeAdded is a document only present in the later manifest.
+	ChangeAdded ChangeKind = iota
+	// ChangeRemoved is a document only present in the earlier manifest.
+	ChangeRemoved
+	// ChangeModified is a document whose content changed.
+	ChangeModified
+	// ChangeMoved is a document whose name, folder or location changed.
+	ChangeMoved
+	// ChangeRetagged is a document whose tags changed.
+	ChangeRetagged
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	case ChangeMoved:
+		return "moved"
+	case ChangeRetagged:
+		return "retagged"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", int(k))
+	}
+}
+
+// MarshalText encodes the kind as its name.
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes the name of a kind.
+func (k *ChangeKind) UnmarshalText(text []byte) error {
+	for kind := ChangeAdded; kind <= ChangeRetagged; kind++ {
+		if kind.String() == string(text) {
+			*k = kind
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", errChangeKind, text)
+}
+
+// Change is a difference on a document between two manifests.
+// A document can have several changes, such as being moved and retagged.
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+	// Before is the document in the earlier manifest, nil when added.
+	Before *Document `json:"before"`
+	// After is the document in the later manifest, nil when removed.
+	After *Document `json:"after"`
+}
+
+// Diff returns the changes of the documents from the manifest to the later one, ordered like the documents.
+func (m *Manifest) Diff(later *Manifest) []Change {
+	before := make(map[digiposte.DocumentID]*Document, len(m.Documents))
+	for _, document := range m.Documents {
+		before[document.ID] = document
+	}
+
+	var changes []Change
+
+	for _, after := range later.Documents {
+		previous, ok := before[after.ID]
+		if !ok {
+			changes = append(changes, Change{Kind: ChangeAdded, Before: nil, After: after})
+
+			continue
+		}
+
+		delete(before, after.ID)
+
+		for _, kind := range documentChanges(previous, after) {
+			changes = append(changes, Change{Kind: kind, Before: previous, After: after})
+		}
+	}
+
+	for _, document := range m.Documents {
+		if _, ok := before[document.ID]; ok {
+			changes = append(changes, Change{Kind: ChangeRemoved, Before: document, After: nil})
+		}
+	}
+
+	return changes
+}
+
+func documentChanges(before, after *Document) []ChangeKind {
+	var kinds []ChangeKind
+
+	if before.SHA256 != after.SHA256 || before.Size != after.Size {
+		kinds = append(kinds, ChangeModified)
+	}
+
+	if before.Name != after.Name || before.FolderID != after.FolderID || before.Location != after.Location {
+		kinds = append(kinds, ChangeMoved)
+	}
+
+	if !utils.SameElements(before.Tags, after.Tags) {
+		kinds = append(kinds, ChangeRetagged)
+	}
+
+	return kinds
+}
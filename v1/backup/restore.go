@@ -0,0 +1,474 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+// RestoreOption configures a restore.
+type RestoreOption func(o *restoreOptions)
+
+type restoreOptions struct {
+	dryRun       bool
+	skipExisting bool
+	folder       string
+	previous     []*Reader
+}
+
+// DryRun computes what the restore would do, without changing the account.
+// The Mapping of the result only holds the existing folders and documents.
+func DryRun() RestoreOption {
+	return func(o *restoreOptions) {
+		o.dryRun = true
+	}
+}
+
+// SkipExisting keeps the documents already present in their folder with the same name, instead of uploading a copy.
+// The existing document is then used in the mapping.
+func SkipExisting() RestoreOption {
+	return func(o *restoreOptions) {
+		o.skipExisting = true
+	}
+}
+
+// IntoFolder restores the backup into the folder with the given path, created if needed, instead of the root.
+func IntoFolder(folderPath string) RestoreOption {
+	return func(o *restoreOptions) {
+		o.folder = folderPath
+	}
+}
+
+// WithPreviousArchives gives the archives of the previous backups an incremental backup is based on.
+// They hold the content of the documents the incremental backup left unchanged.
+func WithPreviousArchives(archives ...*Reader) RestoreOption {
+	return func(o *restoreOptions) {
+		o.previous = append(o.previous, archives...)
+	}
+}
+
+// Mapping maps the IDs of the backup to the IDs of the restored folders and documents.
+type Mapping struct {
+	Folders   map[digiposte.FolderID]digiposte.FolderID     `json:"folders"`
+	Documents map[digiposte.DocumentID]digiposte.DocumentID `json:"documents"`
+}
+
+// RestoreResult is the result of Restore.
+type RestoreResult struct {
+	Mapping Mapping
+	// CreatedFolders are the folders of the backup created in the account.
+	CreatedFolders []*Folder
+	// Uploaded are the documents of the backup uploaded to the account, or to upload in dry-run mode.
+	Uploaded []*Document
+	// Skipped are the documents already present, with SkipExisting.
+	Skipped []*Document
+}
+
+// DocumentError is the failure of the restore of a document.
+type DocumentError struct {
+	Document *Document
+	Err      error
+}
+
+func (e *DocumentError) Error() string {
+	return fmt.Sprintf("restore %s (%s): %v", e.Document.ID, e.Document.File, e.Err)
+}
+
+func (e *DocumentError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	errMissingContent = errors.New("missing content in the archive")
+	errMissingArchive = errors.New("missing the archives of the previous backups")
+)
+
+// Restore recreates the folders and the documents of the backup in the account, and reapplies their tags.
+// The existing folders with the same name are reused. The health documents are uploaded as such.
+//
+// The trashed folders and documents, the shares and the read and favorite states are not restored.
+// The failed documents do not stop the others: their errors are joined as *DocumentError.
+// The content of the documents left unchanged by an incremental backup is in the archives of the previous
+// backups, given with WithPreviousArchives: Restore fails before changing the account if one is missing.
+func Restore(ctx context.Context, client *digiposte.Client, archive *Reader, opts ...RestoreOption) (
+	*RestoreResult,
+	error,
+) {
+	config := &restoreOptions{
+		dryRun:       false,
+		skipExisting: false,
+		folder:       "",
+		previous:     nil,
+	}
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	restorer := &restorer{
+		client:   client,
+		archives: append([]*Reader{archive}, config.previous...),
+		config:   config,
+		result: &RestoreResult{
+			Mapping: Mapping{
+				Folders:   make(map[digiposte.FolderID]digiposte.FolderID),
+				Documents: make(map[digiposte.DocumentID]digiposte.DocumentID),
+			},
+			CreatedFolders: []*Folder{},
+			Uploaded:       []*Document{},
+			Skipped:        []*Document{},
+		},
+		targets:  make(map[digiposte.FolderID]*target),
+		existing: make(map[digiposte.FolderID]map[string]digiposte.DocumentID),
+		pending:  make(map[*Reader]map[string]*pendingDocument),
+	}
+
+	if err := restorer.checkArchives(); err != nil {
+		return nil, err
+	}
+
+	if err := restorer.restoreFolders(ctx); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+
+	if err := restorer.planDocuments(ctx); err != nil {
+		return nil, err
+	}
+
+	if !config.dryRun {
+		errs = append(errs, restorer.upload(ctx)...)
+
+		if err := restorer.tag(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return restorer.result, errors.Join(errs...)
+}
+
+// target is the folder of the account a folder of the backup is restored to.
+type target struct {
+	// folder is nil if the folder does not exist yet, in dry-run mode.
+	folder *digiposte.Folder
+}
+
+// pendingDocument is a document to upload, waiting for its content in the archive.
+type pendingDocument struct {
+	document *Document
+	folderID digiposte.FolderID
+}
+
+type restorer struct {
+	client *digiposte.Client
+	// archives are the archive of the backup, then the archives of the previous backups.
+	archives []*Reader
+	config   *restoreOptions
+	result   *RestoreResult
+
+	// targets are the restored folders, by ID in the backup. The root of the backup is RootFolderID.
+	targets map[digiposte.FolderID]*target
+	// existing are the names of the documents of the account, by folder, loaded on first use.
+	existing map[digiposte.FolderID]map[string]digiposte.DocumentID
+	// pending are the documents to upload, by archive holding their content, then by archive file.
+	pending map[*Reader]map[string]*pendingDocument
+}
+
+// archiveOf returns the archive holding the content of the document, or nil if it is missing.
+func (r *restorer) archiveOf(document *Document) *Reader {
+	for _, archive := range r.archives {
+		if archive.Manifest.Archived(document) {
+			return archive
+		}
+	}
+
+	return nil
+}
+
+// checkArchives fails if the content of a document to restore is in none of the archives.
+func (r *restorer) checkArchives() error {
+	missing := make(map[string]struct{})
+
+	for _, document := range r.archives[0].Manifest.Documents {
+		if !document.Trashed && r.archiveOf(document) == nil {
+			missing[document.ArchivedAt.UTC().Format(time.RFC3339)] = struct{}{}
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	backups := make([]string, 0, len(missing))
+	for createdAt := range missing {
+		backups = append(backups, createdAt)
+	}
+
+	sort.Strings(backups)
+
+	return fmt.Errorf("%w: created at %s", errMissingArchive, strings.Join(backups, ", "))
+}
+
+// restoreFolders finds or creates the folders of the backup, parents first.
+func (r *restorer) restoreFolders(ctx context.Context) error {
+	root, err := r.rootFolder(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.targets[digiposte.RootFolderID] = &target{folder: root}
+
+	folders := make([]*Folder, 0, len(r.archives[0].Manifest.Folders))
+
+	for _, folder := range r.archives[0].Manifest.Folders {
+		if !folder.Trashed {
+			folders = append(folders, folder)
+		}
+	}
+
+	sort.SliceStable(folders, func(i, j int) bool {
+		return folders[i].Path < folders[j].Path
+	})
+
+	for _, folder := range folders {
+		parent, ok := r.targets[folder.ParentID]
+		if !ok {
+			return fmt.Errorf("folder %s: %w: parent %s", folder.Path, errMissingParent, folder.ParentID)
+		}
+
+		restored, err := r.restoreFolder(ctx, parent, folder)
+		if err != nil {
+			return fmt.Errorf("folder %s: %w", folder.Path, err)
+		}
+
+		r.targets[folder.ID] = restored
+	}
+
+	return nil
+}
+
+var errMissingParent = errors.New("the parent folder is not in the backup")
+
+// rootFolder returns the folder the backup is restored into, or nil if it does not exist yet in dry-run mode.
+func (r *restorer) rootFolder(ctx context.Context) (*digiposte.Folder, error) {
+	if r.config.folder == "" {
+		folders, err := r.client.ListFolders(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list folders: %w", err)
+		}
+
+		return &digiposte.Folder{
+			InternalID:    digiposte.RootFolderID,
+			Name:          "",
+			CreatedAt:     time.Time{},
+			UpdatedAt:     time.Time{},
+			DocumentCount: 0,
+			Folders:       folders.Folders,
+			ParentID:      digiposte.RootFolderID,
+		}, nil
+	}
+
+	if r.config.dryRun {
+		folder, err := r.client.NewResolver().ResolveFolder(ctx, r.config.folder)
+		if errors.Is(err, digiposte.ErrNotFound) {
+			return nil, nil //nolint:nilnil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("resolve %q: %w", r.config.folder, err)
+		}
+
+		return folder, nil
+	}
+
+	folder, err := r.client.MkdirAll(ctx, digiposte.RootFolderID, r.config.folder)
+	if err != nil {
+		return nil, fmt.Errorf("create %q: %w", r.config.folder, err)
+	}
+
+	return folder, nil
+}
+
+func (r *restorer) restoreFolder(ctx context.Context, parent *target, folder *Folder) (*target, error) {
+	if parent.folder != nil {
+		for _, child := range parent.folder.Folders {
+			if child.Name == folder.Name {
+				r.result.Mapping.Folders[folder.ID] = child.InternalID
+
+				return &target{folder: child}, nil
+			}
+		}
+	}
+
+	r.result.CreatedFolders = append(r.result.CreatedFolders, folder)
+
+	if r.config.dryRun {
+		return &target{folder: nil}, nil
+	}
+
+	created, err := r.client.CreateFolder(ctx, parent.folder.InternalID, folder.Name)
+	if err != nil {
+		return nil, fmt.Errorf("create folder: %w", err)
+	}
+
+	r.result.Mapping.Folders[folder.ID] = created.InternalID
+
+	return &target{folder: created}, nil
+}
+
+// planDocuments sorts the documents between the skipped ones and the ones to upload.
+func (r *restorer) planDocuments(ctx context.Context) error {
+	for _, document := range r.archives[0].Manifest.Documents {
+		if document.Trashed {
+			continue
+		}
+
+		parent, ok := r.targets[document.FolderID]
+		if !ok {
+			parent = r.targets[digiposte.RootFolderID]
+		}
+
+		if r.config.skipExisting && parent.folder != nil {
+			existingID, err := r.existingDocument(ctx, parent.folder.InternalID, document.Name)
+			if err != nil {
+				return err
+			}
+
+			if existingID != "" {
+				r.result.Mapping.Documents[document.ID] = existingID
+				r.result.Skipped = append(r.result.Skipped, document)
+
+				continue
+			}
+		}
+
+		if r.config.dryRun {
+			r.result.Uploaded = append(r.result.Uploaded, document)
+
+			continue
+		}
+
+		archive := r.archiveOf(document)
+		if r.pending[archive] == nil {
+			r.pending[archive] = make(map[string]*pendingDocument)
+		}
+
+		r.pending[archive][document.File] = &pendingDocument{document: document, folderID: parent.folder.InternalID}
+	}
+
+	return nil
+}
+
+// existingDocument returns the ID of the document with the given name in the folder, or an empty ID.
+func (r *restorer) existingDocument(ctx context.Context, folderID digiposte.FolderID, name string) (
+	digiposte.DocumentID,
+	error,
+) {
+	names, ok := r.existing[folderID]
+	if !ok {
+		documents, err := r.client.SearchDocumentsIter(ctx, folderID).All()
+		if err != nil {
+			return "", fmt.Errorf("search documents: %w", err)
+		}
+
+		names = make(map[string]digiposte.DocumentID, len(documents))
+
+		for _, document := range documents {
+			names[document.Name] = document.InternalID
+		}
+
+		r.existing[folderID] = names
+	}
+
+	return names[name], nil
+}
+
+// upload uploads the pending documents, archive by archive, in the order of each archive.
+func (r *restorer) upload(ctx context.Context) []error {
+	var errs []error
+
+	for _, archive := range r.archives {
+		pendings, ok := r.pending[archive]
+		if !ok {
+			continue
+		}
+
+		delete(r.pending, archive)
+
+		err := archive.walk(func(name string, content io.Reader) error {
+			pending, ok := pendings[name]
+			if !ok {
+				return nil
+			}
+
+			delete(pendings, name)
+
+			if err := r.uploadDocument(ctx, pending, content); err != nil {
+				errs = append(errs, &DocumentError{Document: pending.document, Err: err})
+			}
+
+			return ctx.Err() //nolint:wrapcheck
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		for _, pending := range pendings {
+			errs = append(errs, &DocumentError{Document: pending.document, Err: errMissingContent})
+		}
+	}
+
+	return errs
+}
+
+func (r *restorer) uploadDocument(ctx context.Context, pending *pendingDocument, content io.Reader) error {
+	docType := digiposte.DocumentTypeBasic
+	if pending.document.Health {
+		docType = digiposte.DocumentTypeHealth
+	}
+
+	data := &digiposte.KnownSizeReader{Reader: content, Size: pending.document.ContentSize}
+
+	created, err := r.client.CreateDocument(ctx, pending.folderID, pending.document.Name, data, docType)
+	if err != nil {
+		return fmt.Errorf("create document: %w", err)
+	}
+
+	r.result.Mapping.Documents[pending.document.ID] = created.InternalID
+	r.result.Uploaded = append(r.result.Uploaded, pending.document)
+
+	return nil
+}
+
+// tag reapplies the tags of the uploaded documents.
+func (r *restorer) tag(ctx context.Context) error {
+	tags := make(map[digiposte.DocumentID][]digiposte.DocumentTag)
+
+	for _, document := range r.result.Uploaded {
+		newID, ok := r.result.Mapping.Documents[document.ID]
+		if !ok || len(document.Tags) == 0 {
+			continue
+		}
+
+		for _, tag := range document.Tags {
+			tags[newID] = append(tags[newID], digiposte.DocumentTag(tag))
+		}
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	if err := r.client.MultiTag(ctx, tags); err != nil {
+		return fmt.Errorf("tag documents: %w", err)
+	}
+
+	return nil
+}
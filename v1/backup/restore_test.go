@@ -0,0 +1,150 @@
+package backup_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+	"github.com/holyhope/digiposte-go-sdk/v1/backup"
+	"github.com/holyhope/digiposte-go-sdk/v1/digipostetest"
+)
+
+var _ = ginkgo.Describe("Restore", func() {
+	var (
+		source  *digiposte.Client
+		archive *backup.Reader
+		avis    *digiposte.Document
+		target  *digiposte.Client
+	)
+
+	newClient := func(ctx ginkgo.SpecContext) *digiposte.Client {
+		server := digipostetest.NewServer()
+		ginkgo.DeferCleanup(server.Close)
+
+		client, err := digiposte.NewAuthenticatedClient(ctx, new(http.Client), server.Config())
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		return client
+	}
+
+	write := func(ctx ginkgo.SpecContext, options ...backup.Option) *backup.Reader {
+		buffer := new(bytes.Buffer)
+
+		_, err := backup.Write(ctx, source, buffer, backup.FormatTarGz, options...)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		reader, err := backup.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()), backup.FormatTarGz)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		return reader
+	}
+
+	ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+		source = newClient(ctx)
+
+		folder, err := source.MkdirAll(ctx, digiposte.RootFolderID, "Impôts/2024")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		avis, err = source.CreateDocument(ctx, folder.InternalID, "avis.txt", strings.NewReader("the avis"),
+			digiposte.DocumentTypeBasic)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		_, err = source.CreateDocument(ctx, digiposte.RootFolderID, "ordonnance.txt", strings.NewReader("ordonnance"),
+			digiposte.DocumentTypeHealth)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		gomega.Expect(source.MultiTag(ctx, map[digiposte.DocumentID][]digiposte.DocumentTag{
+			avis.InternalID: {"taxes", "2024"},
+		})).To(gomega.Succeed())
+
+		archive = write(ctx)
+		target = newClient(ctx)
+	})
+
+	content := func(ctx ginkgo.SpecContext, documentID digiposte.DocumentID) string {
+		reader, _, err := target.DocumentContent(ctx, documentID)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		data, err := io.ReadAll(reader)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		return string(data)
+	}
+
+	ginkgo.It("Should recreate the folders, the documents and the tags", func(ctx ginkgo.SpecContext) {
+		result, err := backup.Restore(ctx, target, archive)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(result.CreatedFolders).To(gomega.HaveLen(2))
+		gomega.Expect(result.Uploaded).To(gomega.HaveLen(2))
+
+		entry, err := target.StatPath(ctx, "Impôts/2024/avis.txt")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(result.Mapping.Documents).To(gomega.HaveKeyWithValue(avis.InternalID, entry.Document.InternalID))
+		gomega.Expect(entry.Document.UserTags).To(gomega.ConsistOf("taxes", "2024"))
+		gomega.Expect(content(ctx, entry.Document.InternalID)).To(gomega.Equal("the avis"))
+
+		health, err := target.SearchDocuments(ctx, digiposte.RootFolderID, digiposte.HealthDocuments())
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(health.Documents).To(gomega.HaveLen(1))
+		gomega.Expect(health.Documents[0].Name).To(gomega.Equal("ordonnance.txt"))
+	})
+
+	ginkgo.It("Should not change the account in dry-run mode", func(ctx ginkgo.SpecContext) {
+		result, err := backup.Restore(ctx, target, archive, backup.DryRun(), backup.IntoFolder("Restored"))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(result.CreatedFolders).To(gomega.HaveLen(2))
+		gomega.Expect(result.Uploaded).To(gomega.HaveLen(2))
+		gomega.Expect(result.Mapping.Documents).To(gomega.BeEmpty())
+
+		folders, err := target.ListFolders(ctx)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(folders.Folders).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should skip the existing documents", func(ctx ginkgo.SpecContext) {
+		first, err := backup.Restore(ctx, target, archive, backup.IntoFolder("Restored/2025"))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		second, err := backup.Restore(ctx, target, archive, backup.IntoFolder("Restored/2025"), backup.SkipExisting())
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(second.CreatedFolders).To(gomega.BeEmpty())
+		gomega.Expect(second.Uploaded).To(gomega.BeEmpty())
+		gomega.Expect(second.Skipped).To(gomega.HaveLen(2))
+		gomega.Expect(second.Mapping).To(gomega.Equal(first.Mapping))
+
+		_, err = target.StatPath(ctx, "Restored/2025/Impôts/2024/avis.txt")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+	})
+
+	ginkgo.It("Should restore an incremental backup with the previous archives", func(ctx ginkgo.SpecContext) {
+		_, err := source.CreateDocument(ctx, digiposte.RootFolderID, "new.txt", strings.NewReader("new"),
+			digiposte.DocumentTypeBasic)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		incremental := write(ctx, backup.Incremental(archive.Manifest))
+
+		_, err = backup.Restore(ctx, target, incremental)
+		gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("previous backups")))
+
+		folders, err := target.ListFolders(ctx)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(folders.Folders).To(gomega.BeEmpty())
+
+		result, err := backup.Restore(ctx, target, incremental, backup.WithPreviousArchives(archive))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(result.Uploaded).To(gomega.HaveLen(3))
+
+		entry, err := target.StatPath(ctx, "Impôts/2024/avis.txt")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(content(ctx, entry.Document.InternalID)).To(gomega.Equal("the avis"))
+
+		entry, err = target.StatPath(ctx, "new.txt")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(content(ctx, entry.Document.InternalID)).To(gomega.Equal("new"))
+	})
+})
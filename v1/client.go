@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
 	"strings"
+	"sync/atomic"
 
 	"golang.org/x/oauth2"
 
@@ -23,8 +26,12 @@ import (
 type Client struct {
 	*clientHelper
 
-	apiURL      string
-	documentURL string
+	apiURL       string
+	documentURL  string
+	sessionStore SessionStore
+
+	// fullContentSearch is set once the offer is known to include the full content search.
+	fullContentSearch atomic.Bool
 }
 
 // NewClient creates a new Digiposte client.
@@ -49,6 +56,17 @@ type Config struct {
 	SessionListener func(session *Session)
 
 	PreviousSession *Session
+
+	// SessionStore persists the session: it is loaded when PreviousSession is not set,
+	// saved after each login and cleared by Logout.
+	SessionStore SessionStore
+
+	// SessionSaveError is called when the session cannot be saved to the SessionStore.
+	// The client keeps working, but the next run has to login again.
+	SessionSaveError func(err error)
+
+	// ClientOptions are applied to the client created by NewAuthenticatedClient.
+	ClientOptions []ClientOption
 }
 
 // SetupDefault sets up the default values of the configuration.
@@ -87,6 +105,15 @@ func NewAuthenticatedClient(ctx context.Context, httpClient *http.Client, config
 		config = new(Config)
 	}
 
+	if config.PreviousSession == nil && config.SessionStore != nil {
+		session, err := config.SessionStore.Load(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("load session: %w", err)
+		}
+
+		config.PreviousSession = session
+	}
+
 	if err := config.SetupDefault(ctx); err != nil {
 		return nil, fmt.Errorf("setup default config: %w", err)
 	}
@@ -106,11 +133,14 @@ func NewAuthenticatedClient(ctx context.Context, httpClient *http.Client, config
 	httpClient.Jar.SetCookies(documentURL, config.PreviousSession.Cookies)
 
 	tokenSource := &TokenSource{
-		clientHelper: &clientHelper{client: httpClient},
+		clientHelper: &clientHelper{client: httpClient, retryPolicy: nil, rateLimiters: nil, logger: nil},
 		DocumentURL:  config.DocumentURL,
 		GetContext:   nil,
 	}
 
+	// client is set once created, before any request can trigger a login.
+	var client *Client
+
 	authenticatedClient := new(http.Client)
 
 	*authenticatedClient = *httpClient
@@ -125,20 +155,48 @@ func NewAuthenticatedClient(ctx context.Context, httpClient *http.Client, config
 				Listener: func(token *oauth2.Token, cookies []*http.Cookie) {
 					httpClient.Jar.SetCookies(documentURL, cookies)
 
-					config.SessionListener(&Session{
+					session := &Session{
 						Token:   token,
 						Cookies: cookies,
-					})
+					}
+
+					if config.SessionStore != nil {
+						// The token is valid even if it cannot be saved: the next run will login again.
+						// The login itself does not use the context of NewAuthenticatedClient either.
+						if err := config.SessionStore.Save(context.Background(), session); err != nil {
+							client.log().Warn("Failed to save the session", slog.Any("error", err))
+
+							if config.SessionSaveError != nil {
+								config.SessionSaveError(err)
+							}
+						}
+					}
+
+					config.SessionListener(session)
 				},
 			},
 		}),
 	}
 
-	return NewCustomClient(config.APIURL, config.DocumentURL, authenticatedClient), nil
+	options := config.ClientOptions
+	if config.SessionStore != nil {
+		options = append([]ClientOption{WithSessionStore(config.SessionStore)}, options...)
+	}
+
+	client = NewCustomClient(config.APIURL, config.DocumentURL, authenticatedClient, options...)
+
+	// The token requests share the rate limits of the client.
+	tokenSource.rateLimiters = client.rateLimiters
+	tokenSource.logger = client.logger
+
+	return client, nil
 }
 
-// NewClient creates a new Digiposte client.
-func NewCustomClient(apiURL, documentURL string, client *http.Client) *Client {
+// ClientOption configures a Client.
+type ClientOption func(c *Client)
+
+// NewCustomClient creates a new Digiposte client for the given URLs.
+func NewCustomClient(apiURL, documentURL string, client *http.Client, options ...ClientOption) *Client {
 	if client == nil {
 		client = new(http.Client)
 		*client = *http.DefaultClient
@@ -158,11 +216,20 @@ func NewCustomClient(apiURL, documentURL string, client *http.Client) *Client {
 		}
 	}
 
-	return &Client{
-		clientHelper: &clientHelper{client: client},
+	digiposteClient := &Client{
+		clientHelper: &clientHelper{client: client, retryPolicy: nil, rateLimiters: nil, logger: nil},
 		apiURL:       strings.TrimRight(apiURL, "/"),
 		documentURL:  strings.TrimRight(documentURL, "/"),
+		sessionStore: nil,
+
+		fullContentSearch: atomic.Bool{},
+	}
+
+	for _, option := range options {
+		option(digiposteClient)
 	}
+
+	return digiposteClient
 }
 
 const JSONContentType = "application/json"
@@ -202,7 +269,8 @@ func (e *CloseBodyError) Unwrap() error {
 	return e.Err
 }
 
-// RequestError is an error returned when the API returns an error.
+// RequestErrors is the body of an error response of the API.
+// It is wrapped in an *APIError.
 type RequestErrors []struct {
 	ErrorCode string                 `json:"error"`
 	ErrorDesc string                 `json:"error_description,omitempty"`
@@ -241,6 +309,24 @@ func (c *Client) Trash(ctx context.Context, documentIDs []DocumentID, folderIDs
 	return c.call(req, nil)
 }
 
+// untrash moves the given documents and folders out of the trash, in a single request.
+func (c *Client) untrash(ctx context.Context, documentIDs []DocumentID, folderIDs []FolderID) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"document_ids": documentIDs,
+		"folder_ids":   folderIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal body: %w", err)
+	}
+
+	req, err := c.apiRequest(ctx, http.MethodPost, "/v3/file/tree/untrash", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+
+	return c.call(req, nil)
+}
+
 // Delete deletes permanently the given documents and folders.
 func (c *Client) Delete(ctx context.Context, documentIDs []DocumentID, folderIDs []FolderID) error {
 	body, err := json.Marshal(map[string]interface{}{
@@ -279,22 +365,34 @@ func (c *Client) Move(ctx context.Context, destID FolderID, documentIDs []Docume
 	return c.call(req, nil)
 }
 
-// Logout logs out the user.
+// Logout logs out the user and clears the session store, if any.
+// The store is cleared even if the request fails.
 func (c *Client) Logout(ctx context.Context) error {
 	req, err := c.apiRequest(ctx, http.MethodPost, "/v3/profile/logout", nil)
 	if err != nil {
 		return fmt.Errorf("new request: %w", err)
 	}
 
-	return c.call(req, nil)
+	err = c.call(req, nil)
+
+	if c.sessionStore != nil {
+		if deleteErr := c.sessionStore.Delete(ctx); deleteErr != nil {
+			err = errors.Join(err, fmt.Errorf("delete session: %w", deleteErr))
+		}
+	}
+
+	return err
 }
 
 type clientHelper struct {
-	client *http.Client
+	client       *http.Client
+	retryPolicy  *RetryPolicy
+	rateLimiters *rateLimiters
+	logger       *slog.Logger
 }
 
 func (c *clientHelper) call(req *http.Request, result interface{}, expectedStatuses ...int) (finalErr error) {
-	response, err := c.client.Do(req)
+	response, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("failed to request %q: %w", req.URL, err)
 	}
@@ -314,7 +412,7 @@ func (c *clientHelper) call(req *http.Request, result interface{}, expectedStatu
 	}
 
 	if err := c.checkResponse(response, expectedStatuses...); err != nil {
-		return fmt.Errorf("%s to %q: %w", req.Method, req.URL, err)
+		return err
 	}
 
 	if result == nil {
@@ -342,7 +440,7 @@ func (c *clientHelper) checkResponse(response *http.Response, expectedStatuses .
 		return fmt.Errorf("HTTP %s: failed to read response body: %w", response.Status, err)
 	}
 
-	if err := json.Unmarshal(content, errs); err != nil {
+	if err := json.Unmarshal(content, errs); err != nil || len(*errs) == 0 {
 		context := map[string]interface{}{
 			"content":      content,
 			"decode_error": err,
@@ -352,12 +450,12 @@ func (c *clientHelper) checkResponse(response *http.Response, expectedStatuses .
 			context["content-type"] = contentType
 		}
 
-		return &RequestErrors{{
+		errs = &RequestErrors{{
 			ErrorCode: response.Status,
 			ErrorDesc: "failed to decode error response",
 			Context:   context,
 		}}
 	}
 
-	return fmt.Errorf("HTTP %s: %w", response.Status, errs)
+	return newAPIError(response.Request, response.StatusCode, errs)
 }
@@ -37,6 +37,7 @@ var _ = ginkgo.Describe("Client", func() {
 				},
 				SessionListener: nil,
 				PreviousSession: nil,
+				SessionStore:    nil,
 			})).ToNot(gomega.BeNil())
 		})
 	})
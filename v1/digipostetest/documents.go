@@ -0,0 +1,750 @@
+package digipostetest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+type document struct {
+	digiposte.Document
+
+	content     []byte
+	contentType string
+}
+
+func (d *document) trashed() bool {
+	return strings.HasPrefix(d.Location, "TRASH_")
+}
+
+// snapshot returns a copy of the document metadata, safe to use without the lock.
+func (d *document) snapshot() *digiposte.Document {
+	doc := d.Document
+	doc.UserTags = append([]string(nil), d.UserTags...)
+
+	return &doc
+}
+
+// AddDocument stores a document directly, without going through the upload endpoint.
+// It is useful to seed the server with documents located in the inbox.
+func (s *Server) AddDocument(
+	folderID digiposte.FolderID,
+	name string,
+	content []byte,
+	location digiposte.Location,
+) *digiposte.Document {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	doc := s.addDocument(folderID, name, content, location, false)
+
+	return doc.snapshot()
+}
+
+// CertifyDocument sets the certified flag of a document, as the issuer of the document would.
+func (s *Server) CertifyDocument(documentID digiposte.DocumentID, certified bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if doc, ok := s.documents[documentID]; ok {
+		doc.Certified = certified
+	}
+}
+
+// addDocument stores a new document. The caller must hold the lock.
+func (s *Server) addDocument(
+	folderID digiposte.FolderID,
+	name string,
+	content []byte,
+	location digiposte.Location,
+	health bool,
+) *document {
+	mediaType, contentType := detectContentType(content)
+
+	doc := &document{
+		Document: digiposte.Document{
+			InternalID:     digiposte.DocumentID(newID()),
+			Name:           name,
+			CreatedAt:      time.Now().UTC().Truncate(time.Millisecond),
+			Size:           int64(len(content)),
+			MimeType:       mediaType,
+			FolderID:       string(folderID),
+			Location:       location.String(),
+			Shared:         false,
+			Read:           false,
+			HealthDocument: health,
+			UserTags:       []string{},
+			Favorite:       false,
+			Certified:      false,
+		},
+		content:     content,
+		contentType: contentType,
+	}
+
+	s.documents[doc.InternalID] = doc
+
+	return doc
+}
+
+// detectContentType returns the media type of the content, and the Content-Type header
+// formatted the way Digiposte does (e.g. "text/plain;charset=UTF-8").
+func detectContentType(content []byte) (string, string) {
+	mediaType, params, err := mime.ParseMediaType(http.DetectContentType(content))
+	if err != nil {
+		return "application/octet-stream", "application/octet-stream"
+	}
+
+	if charset, ok := params["charset"]; ok {
+		return mediaType, mediaType + ";charset=" + strings.ToUpper(charset)
+	}
+
+	return mediaType, mediaType
+}
+
+func (s *Server) handleDocuments(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodGet) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	documents := s.filterDocuments(func(d *document) bool {
+		return !d.trashed() && d.FolderID == string(digiposte.RootFolderID)
+	})
+
+	writeJSON(writer, http.StatusOK, &digiposte.SearchDocumentsResult{
+		Count:      int64(len(documents)),
+		Index:      0,
+		MaxResults: int64(len(documents)),
+		Documents:  documents,
+	})
+}
+
+// filterDocuments returns a snapshot of the documents matching the predicate, sorted by name.
+// The caller must hold the lock.
+func (s *Server) filterDocuments(predicate func(*document) bool) []*digiposte.Document {
+	documents := make([]*digiposte.Document, 0, len(s.documents))
+
+	for _, doc := range s.documents {
+		if predicate(doc) {
+			documents = append(documents, doc.snapshot())
+		}
+	}
+
+	sort.Slice(documents, func(i, j int) bool {
+		if documents[i].Name != documents[j].Name {
+			return documents[i].Name < documents[j].Name
+		}
+
+		return documents[i].InternalID < documents[j].InternalID
+	})
+
+	return documents
+}
+
+type searchRequest struct {
+	FolderID    *string    `json:"folder_id"`
+	Recursive   bool       `json:"recursive"`
+	Locations   []string   `json:"locations"`
+	Health      *bool      `json:"health"`
+	Shared      *bool      `json:"document_shared"`
+	Read        *bool      `json:"document_read"`
+	Certified   *bool      `json:"document_certified"`
+	Favorite    *bool      `json:"favorite"`
+	UserTags    []string   `json:"user_tags"`
+	UserRemoval bool       `json:"user_removal"`
+	Title       *string    `json:"title"`
+	FullText    *string    `json:"fulltext"`
+	StartDate   *time.Time `json:"start_date"`
+	EndDate     *time.Time `json:"end_date"`
+	MimeTypes   []string   `json:"mimetypes"`
+	MinSize     *int64     `json:"min_size"`
+	MaxSize     *int64     `json:"max_size"`
+
+	// folderIDs are the folders searched recursively, when Recursive is set.
+	folderIDs map[string]bool
+}
+
+func (r *searchRequest) matches(doc *document) bool {
+	if !r.matchesFolder(doc) || !r.matchesLocation(doc) || !r.matchesContent(doc) {
+		return false
+	}
+
+	for _, filter := range []struct {
+		expected *bool
+		actual   bool
+	}{
+		{r.Health, doc.HealthDocument},
+		{r.Shared, doc.Shared},
+		{r.Read, doc.Read},
+		{r.Certified, doc.Certified},
+		{r.Favorite, doc.Favorite},
+	} {
+		if filter.expected != nil && *filter.expected != filter.actual {
+			return false
+		}
+	}
+
+	for _, tag := range r.UserTags {
+		if !contains(doc.UserTags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *searchRequest) matchesFolder(doc *document) bool {
+	switch {
+	case r.FolderID == nil:
+		return true
+	case r.Recursive:
+		return r.folderIDs[doc.FolderID]
+	default:
+		return *r.FolderID == doc.FolderID
+	}
+}
+
+func (r *searchRequest) matchesLocation(doc *document) bool {
+	if r.UserRemoval {
+		return doc.trashed()
+	}
+
+	if len(r.Locations) == 0 {
+		return !doc.trashed()
+	}
+
+	return contains(r.Locations, doc.Location)
+}
+
+// matchesContent applies the text, date, mime type and size filters.
+func (r *searchRequest) matchesContent(doc *document) bool {
+	switch {
+	case r.Title != nil && !strings.Contains(strings.ToLower(doc.Name), strings.ToLower(*r.Title)):
+		return false
+	case r.FullText != nil && !strings.Contains(strings.ToLower(string(doc.content)), strings.ToLower(*r.FullText)):
+		return false
+	case r.StartDate != nil && doc.CreatedAt.Before(*r.StartDate):
+		return false
+	case r.EndDate != nil && doc.CreatedAt.After(*r.EndDate):
+		return false
+	case len(r.MimeTypes) > 0 && !contains(r.MimeTypes, doc.MimeType):
+		return false
+	case r.MinSize != nil && doc.Size < *r.MinSize:
+		return false
+	case r.MaxSize != nil && doc.Size > *r.MaxSize:
+		return false
+	default:
+		return true
+	}
+}
+
+const defaultMaxResults = 100
+
+func (s *Server) handleSearchDocuments(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodPost) {
+		return
+	}
+
+	search := new(searchRequest)
+	if !readJSON(writer, req, search) {
+		return
+	}
+
+	index, maxResults, err := pagination(req.URL.Query())
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, ErrorCodeBadRequest, err.Error())
+
+		return
+	}
+
+	less, err := documentOrder(req.URL.Query())
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, ErrorCodeBadRequest, err.Error())
+
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if search.FullText != nil && !s.profile.Offer.HasFullContentSearchAbility {
+		writeError(writer, http.StatusForbidden, ErrorCodeForbidden, "The offer does not include the full content search.")
+
+		return
+	}
+
+	if search.Recursive && search.FolderID != nil {
+		search.folderIDs = s.subtreeIDs(digiposte.FolderID(*search.FolderID))
+	}
+
+	documents := s.filterDocuments(search.matches)
+
+	sort.SliceStable(documents, func(i, j int) bool {
+		return less(documents[i], documents[j])
+	})
+
+	writeJSON(writer, http.StatusOK, &digiposte.SearchDocumentsResult{
+		Count:      int64(len(documents)),
+		Index:      index,
+		MaxResults: maxResults,
+		Documents:  page(documents, index, maxResults),
+	})
+}
+
+var errInvalidSort = errors.New("sort must be TITLE, DATE or SIZE and direction ASC or DESC")
+
+// documentOrder returns the order of the search results requested by the sort and direction query parameters.
+func documentOrder(query url.Values) (func(a, b *digiposte.Document) bool, error) {
+	var less func(a, b *digiposte.Document) bool
+
+	switch query.Get("sort") {
+	case "", "TITLE":
+		less = func(a, b *digiposte.Document) bool { return a.Name < b.Name }
+	case "DATE":
+		less = func(a, b *digiposte.Document) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	case "SIZE":
+		less = func(a, b *digiposte.Document) bool { return a.Size < b.Size }
+	default:
+		return nil, errInvalidSort
+	}
+
+	switch query.Get("direction") {
+	case "", "ASC":
+		return less, nil
+	case "DESC":
+		return func(a, b *digiposte.Document) bool { return less(b, a) }, nil
+	default:
+		return nil, errInvalidSort
+	}
+}
+
+var errInvalidPagination = errors.New("index and max_results must be positive integers")
+
+func pagination(query url.Values) (int64, int64, error) {
+	index, maxResults := int64(0), int64(defaultMaxResults)
+
+	if value := query.Get("index"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 0 {
+			return 0, 0, errInvalidPagination
+		}
+
+		index = parsed
+	}
+
+	if value := query.Get("max_results"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed <= 0 {
+			return 0, 0, errInvalidPagination
+		}
+
+		maxResults = parsed
+	}
+
+	return index, maxResults, nil
+}
+
+func page[T any](items []T, index, maxResults int64) []T {
+	if index >= int64(len(items)) {
+		return []T{}
+	}
+
+	end := index + maxResults
+	if end > int64(len(items)) {
+		end = int64(len(items))
+	}
+
+	return items[index:end]
+}
+
+// handleDocument handles PUT /v3/document/{id}/rename/{name}.
+func (s *Server) handleDocument(writer http.ResponseWriter, req *http.Request) {
+	segments, ok := pathSegments(req, "/v3/document/")
+	if !ok || len(segments) != 3 || segments[1] != "rename" {
+		writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Unknown endpoint.")
+
+		return
+	}
+
+	if !allowMethod(writer, req, http.MethodPut) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	doc, ok := s.documents[digiposte.DocumentID(segments[0])]
+	if !ok {
+		writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Document not found.")
+
+		return
+	}
+
+	doc.Name = segments[2]
+
+	writeJSON(writer, http.StatusOK, doc.snapshot())
+}
+
+func (s *Server) handleCopyDocuments(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodPost) {
+		return
+	}
+
+	var body struct {
+		Documents []digiposte.DocumentID `json:"documents"`
+	}
+
+	if !readJSON(writer, req, &body) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, id := range body.Documents {
+		if _, ok := s.documents[id]; !ok {
+			writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Document "+string(id)+" not found.")
+
+			return
+		}
+	}
+
+	documents := make([]*digiposte.Document, 0, len(body.Documents))
+
+	for _, id := range body.Documents {
+		original := s.documents[id]
+
+		location, _ := parseLocation(original.Location)
+
+		doc := s.addDocument(
+			digiposte.FolderID(original.FolderID),
+			original.Name,
+			original.content,
+			location,
+			original.HealthDocument,
+		)
+		doc.UserTags = append(doc.UserTags, original.UserTags...)
+
+		documents = append(documents, doc.snapshot())
+	}
+
+	writeJSON(writer, http.StatusOK, &digiposte.SearchDocumentsResult{
+		Count:      int64(len(documents)),
+		Index:      0,
+		MaxResults: int64(len(documents)),
+		Documents:  documents,
+	})
+}
+
+func (s *Server) handleMultiTag(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodPost) {
+		return
+	}
+
+	var body struct {
+		Tags map[digiposte.DocumentID][]string `json:"tags"`
+	}
+
+	if !readJSON(writer, req, &body) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for id := range body.Tags {
+		if _, ok := s.documents[id]; !ok {
+			writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Document "+string(id)+" not found.")
+
+			return
+		}
+	}
+
+	for id, tags := range body.Tags {
+		doc := s.documents[id]
+
+		for _, tag := range tags {
+			if !contains(doc.UserTags, tag) {
+				doc.UserTags = append(doc.UserTags, tag)
+			}
+		}
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleMultiUntag(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodPost) {
+		return
+	}
+
+	var body struct {
+		Tags map[digiposte.DocumentID][]string `json:"tags"`
+	}
+
+	if !readJSON(writer, req, &body) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for id := range body.Tags {
+		if _, ok := s.documents[id]; !ok {
+			writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Document "+string(id)+" not found.")
+
+			return
+		}
+	}
+
+	for id, tags := range body.Tags {
+		doc := s.documents[id]
+		kept := make([]string, 0, len(doc.UserTags))
+
+		for _, tag := range doc.UserTags {
+			if !contains(tags, tag) {
+				kept = append(kept, tag)
+			}
+		}
+
+		doc.UserTags = kept
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// handleDocumentsState handles the requests changing the favorite and read flags of documents.
+func (s *Server) handleDocumentsState(update func(doc *document, favorite bool)) http.HandlerFunc {
+	return func(writer http.ResponseWriter, req *http.Request) {
+		if !allowMethod(writer, req, http.MethodPost) {
+			return
+		}
+
+		var body struct {
+			DocumentIDs []digiposte.DocumentID `json:"document_ids"`
+			Favorite    bool                   `json:"favorite"`
+		}
+
+		if !readJSON(writer, req, &body) {
+			return
+		}
+
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		for _, id := range body.DocumentIDs {
+			if _, ok := s.documents[id]; !ok {
+				writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Document "+string(id)+" not found.")
+
+				return
+			}
+		}
+
+		for _, id := range body.DocumentIDs {
+			update(s.documents[id], body.Favorite)
+		}
+
+		writer.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) handleUserTags(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodGet) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	tags := make(map[digiposte.DocumentTag]int)
+
+	for _, doc := range s.documents {
+		if doc.trashed() {
+			continue
+		}
+
+		for _, tag := range doc.UserTags {
+			tags[digiposte.DocumentTag(tag)]++
+		}
+	}
+
+	writeJSON(writer, http.StatusOK, &digiposte.UserTags{Tags: tags})
+}
+
+// handleCreateDocument handles the multipart upload of a document.
+// Fields are accepted in any order.
+func (s *Server) handleCreateDocument(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodPost) {
+		return
+	}
+
+	upload, err := readUpload(req)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, ErrorCodeBadRequest, err.Error())
+
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.folderExists(upload.folderID) {
+		writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Folder not found.")
+
+		return
+	}
+
+	doc := s.addDocument(upload.folderID, upload.title, upload.content, digiposte.LocationSafe, upload.health)
+
+	writeJSON(writer, http.StatusOK, doc.snapshot())
+}
+
+type upload struct {
+	title    string
+	folderID digiposte.FolderID
+	health   bool
+	content  []byte
+}
+
+var (
+	errMissingTitle    = errors.New("title is required")
+	errMissingArchive  = errors.New("archive is required")
+	errArchiveSize     = errors.New("archive_size does not match the archive")
+	errUnexpectedField = errors.New("unexpected field")
+)
+
+func readUpload(req *http.Request) (*upload, error) {
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("multipart reader: %w", err)
+	}
+
+	result := new(upload)
+	expectedSize := int64(-1)
+	hasArchive := false
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("next part: %w", err)
+		}
+
+		value, err := io.ReadAll(part)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", part.FormName(), err)
+		}
+
+		switch part.FormName() {
+		case "archive":
+			result.content = value
+			hasArchive = true
+		case "archive_size":
+			expectedSize, err = strconv.ParseInt(string(value), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse archive_size: %w", err)
+			}
+		case "title":
+			result.title = string(value)
+		case "folder_id":
+			result.folderID = digiposte.FolderID(value)
+		case "health_document":
+			result.health, err = strconv.ParseBool(string(value))
+			if err != nil {
+				return nil, fmt.Errorf("parse health_document: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("%w %q", errUnexpectedField, part.FormName())
+		}
+	}
+
+	switch {
+	case result.title == "":
+		return nil, errMissingTitle
+	case !hasArchive:
+		return nil, errMissingArchive
+	case expectedSize >= 0 && expectedSize != int64(len(result.content)):
+		return nil, errArchiveSize
+	}
+
+	return result, nil
+}
+
+// handleDocumentContent serves the content of a document.
+// Unauthenticated requests are redirected to the authorization page of the API server.
+func (s *Server) handleDocumentContent(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodGet) {
+		return
+	}
+
+	segments, ok := pathSegments(req, "/rest/content/document/")
+	if !ok || len(segments) != 1 {
+		writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Unknown endpoint.")
+
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.isAuthenticated(req) {
+		http.Redirect(writer, req, s.API.URL+"/v3/authorize", http.StatusFound)
+
+		return
+	}
+
+	doc, ok := s.documents[digiposte.DocumentID(segments[0])]
+	if !ok {
+		writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Document not found.")
+
+		return
+	}
+
+	writer.Header().Set("Content-Type", doc.contentType)
+	writer.Header().Set("Content-Length", strconv.Itoa(len(doc.content)))
+	writer.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": doc.Name,
+	}))
+	writer.WriteHeader(http.StatusOK)
+
+	_, _ = writer.Write(doc.content)
+}
+
+func parseLocation(value string) (digiposte.Location, bool) {
+	for _, location := range []digiposte.Location{
+		digiposte.LocationInbox,
+		digiposte.LocationSafe,
+		digiposte.LocationTrashInbox,
+		digiposte.LocationTrashSafe,
+	} {
+		if location.String() == value {
+			return location, true
+		}
+	}
+
+	return digiposte.LocationSafe, false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,470 @@
+package digipostetest
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+type folder struct {
+	id        digiposte.FolderID
+	parentID  digiposte.FolderID
+	name      string
+	createdAt time.Time
+	updatedAt time.Time
+	trashed   bool
+}
+
+// AddFolder stores a folder directly, without going through the API.
+func (s *Server) AddFolder(parentID digiposte.FolderID, name string) *digiposte.Folder {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.folderTree(s.addFolder(parentID, name))
+}
+
+// addFolder stores a new folder. The caller must hold the lock.
+func (s *Server) addFolder(parentID digiposte.FolderID, name string) *folder {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	f := &folder{
+		id:        digiposte.FolderID(newID()),
+		parentID:  parentID,
+		name:      name,
+		createdAt: now,
+		updatedAt: now,
+		trashed:   false,
+	}
+
+	s.folders[f.id] = f
+
+	return f
+}
+
+// folderExists reports whether the folder exists and is not trashed. The caller must hold the lock.
+func (s *Server) folderExists(id digiposte.FolderID) bool {
+	if id == digiposte.RootFolderID {
+		return true
+	}
+
+	f, ok := s.folders[id]
+
+	return ok && !f.trashed
+}
+
+// folderTree returns the folder with its sub-folders. The caller must hold the lock.
+func (s *Server) folderTree(f *folder) *digiposte.Folder {
+	var documentCount int64
+
+	for _, doc := range s.documents {
+		if doc.FolderID == string(f.id) && !doc.trashed() {
+			documentCount++
+		}
+	}
+
+	return &digiposte.Folder{
+		InternalID:    f.id,
+		Name:          f.name,
+		CreatedAt:     f.createdAt,
+		UpdatedAt:     f.updatedAt,
+		DocumentCount: documentCount,
+		Folders:       s.subFolders(f.id, f.trashed),
+		ParentID:      f.parentID,
+	}
+}
+
+// subFolders returns the children of the given folder. The caller must hold the lock.
+func (s *Server) subFolders(parentID digiposte.FolderID, trashed bool) []*digiposte.Folder {
+	folders := make([]*digiposte.Folder, 0)
+
+	for _, f := range s.folders {
+		if f.parentID == parentID && f.trashed == trashed {
+			folders = append(folders, s.folderTree(f))
+		}
+	}
+
+	sortFolders(folders)
+
+	return folders
+}
+
+func sortFolders(folders []*digiposte.Folder) {
+	sort.Slice(folders, func(i, j int) bool {
+		if folders[i].Name != folders[j].Name {
+			return folders[i].Name < folders[j].Name
+		}
+
+		return folders[i].InternalID < folders[j].InternalID
+	})
+}
+
+func (s *Server) handleFolders(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodGet) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	folders := s.subFolders(digiposte.RootFolderID, false)
+
+	writeJSON(writer, http.StatusOK, &digiposte.SearchFoldersResult{
+		Count:      int64(len(folders)),
+		Index:      0,
+		MaxResults: int64(len(folders)),
+		Folders:    folders,
+	})
+}
+
+// handleTrashedFolders returns the top-most trashed folders, with their sub-folders.
+func (s *Server) handleTrashedFolders(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodGet) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	folders := make([]*digiposte.Folder, 0)
+
+	for _, f := range s.folders {
+		if f.trashed && s.folderExists(f.parentID) {
+			folders = append(folders, s.folderTree(f))
+		}
+	}
+
+	sortFolders(folders)
+
+	writeJSON(writer, http.StatusOK, &digiposte.SearchFoldersResult{
+		Count:      int64(len(folders)),
+		Index:      0,
+		MaxResults: int64(len(folders)),
+		Folders:    folders,
+	})
+}
+
+func (s *Server) handleCreateFolder(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodPost) {
+		return
+	}
+
+	var body struct {
+		ParentID digiposte.FolderID `json:"parent_id"`
+		Name     string             `json:"name"`
+	}
+
+	if !readJSON(writer, req, &body) {
+		return
+	}
+
+	if body.Name == "" {
+		writeError(writer, http.StatusBadRequest, ErrorCodeBadRequest, "name is required.")
+
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.folderExists(body.ParentID) {
+		writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Parent folder not found.")
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, s.folderTree(s.addFolder(body.ParentID, body.Name)))
+}
+
+// handleFolder handles PUT /v3/folder/{id}/rename/{name}.
+func (s *Server) handleFolder(writer http.ResponseWriter, req *http.Request) {
+	segments, ok := pathSegments(req, "/v3/folder/")
+	if !ok || len(segments) != 3 || segments[1] != "rename" {
+		writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Unknown endpoint.")
+
+		return
+	}
+
+	if !allowMethod(writer, req, http.MethodPut) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	f, ok := s.folders[digiposte.FolderID(segments[0])]
+	if !ok {
+		writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Folder not found.")
+
+		return
+	}
+
+	f.name = segments[2]
+	f.updatedAt = time.Now().UTC().Truncate(time.Millisecond)
+
+	writeJSON(writer, http.StatusOK, s.folderTree(f))
+}
+
+type treeRequest struct {
+	DocumentIDs []digiposte.DocumentID `json:"document_ids"`
+	FolderIDs   []digiposte.FolderID   `json:"folder_ids"`
+}
+
+// checkTreeRequest checks every item of the request exists. The caller must hold the lock.
+func (s *Server) checkTreeRequest(writer http.ResponseWriter, body *treeRequest) bool {
+	for _, id := range body.DocumentIDs {
+		if _, ok := s.documents[id]; !ok {
+			writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Document "+string(id)+" not found.")
+
+			return false
+		}
+	}
+
+	for _, id := range body.FolderIDs {
+		if _, ok := s.folders[id]; !ok {
+			writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Folder "+string(id)+" not found.")
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// descendants returns the given folder and all its sub-folders. The caller must hold the lock.
+func (s *Server) descendants(id digiposte.FolderID) []*folder {
+	result := []*folder{s.folders[id]}
+
+	for i := 0; i < len(result); i++ {
+		for _, f := range s.folders {
+			if f.parentID == result[i].id {
+				result = append(result, f)
+			}
+		}
+	}
+
+	return result
+}
+
+// subtreeIDs returns the IDs of the given folder and of all its sub-folders. The caller must hold the lock.
+func (s *Server) subtreeIDs(id digiposte.FolderID) map[string]bool {
+	ids := map[string]bool{string(id): true}
+
+	for found := true; found; {
+		found = false
+
+		for _, f := range s.folders {
+			if ids[string(f.parentID)] && !ids[string(f.id)] {
+				ids[string(f.id)] = true
+				found = true
+			}
+		}
+	}
+
+	return ids
+}
+
+func (s *Server) handleTrash(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodPost) {
+		return
+	}
+
+	body := new(treeRequest)
+	if !readJSON(writer, req, body) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.checkTreeRequest(writer, body) {
+		return
+	}
+
+	for _, id := range body.DocumentIDs {
+		trashDocument(s.documents[id])
+	}
+
+	for _, id := range body.FolderIDs {
+		for _, f := range s.descendants(id) {
+			f.trashed = true
+
+			for _, doc := range s.documents {
+				if doc.FolderID == string(f.id) {
+					trashDocument(doc)
+				}
+			}
+		}
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func trashDocument(doc *document) {
+	if !doc.trashed() {
+		doc.Location = "TRASH_" + doc.Location
+	}
+}
+
+func untrashDocument(doc *document) {
+	doc.Location = strings.TrimPrefix(doc.Location, "TRASH_")
+}
+
+func (s *Server) handleUntrash(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodPost) {
+		return
+	}
+
+	body := new(treeRequest)
+	if !readJSON(writer, req, body) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.checkTreeRequest(writer, body) || !s.checkUntrashParents(writer, body) {
+		return
+	}
+
+	for _, id := range body.DocumentIDs {
+		untrashDocument(s.documents[id])
+	}
+
+	for _, id := range body.FolderIDs {
+		for _, f := range s.descendants(id) {
+			f.trashed = false
+
+			for _, doc := range s.documents {
+				if doc.FolderID == string(f.id) {
+					untrashDocument(doc)
+				}
+			}
+		}
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// checkUntrashParents checks that the folder of each item is in the safe, or restored with it.
+// The caller must hold the lock.
+func (s *Server) checkUntrashParents(writer http.ResponseWriter, body *treeRequest) bool {
+	restored := make(map[digiposte.FolderID]bool)
+
+	for _, id := range body.FolderIDs {
+		for _, f := range s.descendants(id) {
+			restored[f.id] = true
+		}
+	}
+
+	for _, id := range body.FolderIDs {
+		if parentID := s.folders[id].parentID; !s.folderExists(parentID) && !restored[parentID] {
+			writeError(writer, http.StatusNotFound, ErrorCodeFolderNotFound, "Parent of folder "+string(id)+" not found.")
+
+			return false
+		}
+	}
+
+	for _, id := range body.DocumentIDs {
+		if parentID := digiposte.FolderID(s.documents[id].FolderID); !s.folderExists(parentID) && !restored[parentID] {
+			writeError(writer, http.StatusNotFound, ErrorCodeFolderNotFound, "Folder of document "+string(id)+" not found.")
+
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Server) handleDelete(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodPost) {
+		return
+	}
+
+	body := new(treeRequest)
+	if !readJSON(writer, req, body) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.checkTreeRequest(writer, body) {
+		return
+	}
+
+	for _, id := range body.DocumentIDs {
+		s.deleteDocument(id)
+	}
+
+	for _, id := range body.FolderIDs {
+		for _, f := range s.descendants(id) {
+			for _, doc := range s.documents {
+				if doc.FolderID == string(f.id) {
+					s.deleteDocument(doc.InternalID)
+				}
+			}
+
+			delete(s.folders, f.id)
+		}
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// deleteDocument removes a document and its share memberships. The caller must hold the lock.
+func (s *Server) deleteDocument(id digiposte.DocumentID) {
+	delete(s.documents, id)
+
+	for _, sh := range s.shares {
+		sh.documents = removeDocumentID(sh.documents, id)
+	}
+}
+
+func (s *Server) handleMove(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodPut) {
+		return
+	}
+
+	destID := digiposte.FolderID(req.URL.Query().Get("to"))
+
+	body := new(treeRequest)
+	if !readJSON(writer, req, body) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.folderExists(destID) {
+		writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Destination folder not found.")
+
+		return
+	}
+
+	if !s.checkTreeRequest(writer, body) {
+		return
+	}
+
+	for _, id := range body.FolderIDs {
+		for _, f := range s.descendants(id) {
+			if f.id == destID {
+				writeError(writer, http.StatusBadRequest, ErrorCodeBadRequest, "Cannot move a folder into itself.")
+
+				return
+			}
+		}
+	}
+
+	for _, id := range body.DocumentIDs {
+		s.documents[id].FolderID = string(destID)
+	}
+
+	for _, id := range body.FolderIDs {
+		s.folders[id].parentID = destID
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,97 @@
+package digipostetest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+// Email is the email address of the user of the fake server.
+const Email = "jane.doe@example.com"
+
+// MaxSafeSize is the size of the safe of the user of the fake server.
+const MaxSafeSize = 5 << 30
+
+func defaultProfile() *digiposte.Profile {
+	profile := new(digiposte.Profile)
+
+	profile.UserInfo = digiposte.UserInfo{
+		InternalID: newID(),
+		Title:      "MME",
+		FirstName:  "Jane",
+		LastName:   "Doe",
+		IDXiti:     nil,
+		Login:      Email,
+		Type:       "PERSON",
+		Locale:     "fr_FR",
+		Email:      Email,
+	}
+	profile.Offer.PID = "FREE"
+	profile.Offer.Type = "FREE"
+	profile.Offer.MaxSafeSize = MaxSafeSize
+	profile.Offer.SubscriptionDate = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	profile.Offer.CommercialName = "Digiposte"
+	profile.Status = "ACTIVE"
+	profile.AuthorName = "Jane Doe"
+	profile.Completion = 100
+
+	return profile
+}
+
+// EnableFullContentSearch includes the full content search in the offer of the user.
+func (s *Server) EnableFullContentSearch() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.profile.Offer.HasFullContentSearchAbility = true
+	s.profile.Capabilities.HasFullContentSearchAbility = true
+}
+
+// safeSize returns the size used by all the documents. The caller must hold the lock.
+func (s *Server) safeSize() int64 {
+	var size int64
+
+	for _, doc := range s.documents {
+		size += doc.Size
+	}
+
+	return size
+}
+
+func (s *Server) handleProfile(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodGet) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	profile := *s.profile
+	size := s.safeSize()
+
+	if req.URL.Query().Get("mode") != digiposte.ProfileModeNoSpaceConsumption.String() {
+		profile.Offer.ActualSafeSize = size
+		profile.Storage = digiposte.Storage{
+			SpaceUsed:        size,
+			SpaceFree:        MaxSafeSize - size,
+			SpaceMax:         MaxSafeSize,
+			SpaceNotComputed: 0,
+		}
+	}
+
+	writeJSON(writer, http.StatusOK, &profile)
+}
+
+func (s *Server) handleProfileSafeSize(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodGet) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	writeJSON(writer, http.StatusOK, &digiposte.ProfileSafeSize{
+		ActualSafeSize: s.safeSize(),
+	})
+}
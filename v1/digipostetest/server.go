@@ -0,0 +1,364 @@
+// Package digipostetest provides an in-memory fake of the Digiposte servers, for offline tests.
+package digipostetest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/holyhope/digiposte-go-sdk/login"
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+// SessionCookieName is the name of the cookie returned by the login method of the fake server.
+const SessionCookieName = "DIGIPOSTE_SESSION"
+
+// TokenLifetime is the lifetime of the tokens issued by the fake server.
+const TokenLifetime = time.Hour
+
+// Error codes returned by the fake server, in the "error" field of the RequestErrors body.
+const (
+	ErrorCodeUnauthorized   = "unauthorized"
+	ErrorCodeForbidden      = "forbidden"
+	ErrorCodeNotFound       = "not_found"
+	ErrorCodeBadRequest     = "bad_request"
+	ErrorCodeMethodNotAllow = "method_not_allowed"
+	ErrorCodeFolderNotFound = "folder_not_found"
+)
+
+// Server is a fake Digiposte backend.
+// It serves the API and the document endpoints from two distinct httptest servers,
+// so that redirections between them behave like on the real hosts.
+// All the state is kept in memory and shared between both servers.
+type Server struct {
+	API      *httptest.Server
+	Document *httptest.Server
+
+	lock      sync.Mutex
+	tokens    map[string]time.Time
+	sessions  map[string]struct{}
+	folders   map[digiposte.FolderID]*folder
+	documents map[digiposte.DocumentID]*document
+	shares    map[digiposte.ShareID]*share
+	profile   *digiposte.Profile
+}
+
+// NewServer starts a new fake Digiposte server.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	server := &Server{
+		API:       nil,
+		Document:  nil,
+		lock:      sync.Mutex{},
+		tokens:    make(map[string]time.Time),
+		sessions:  make(map[string]struct{}),
+		folders:   make(map[digiposte.FolderID]*folder),
+		documents: make(map[digiposte.DocumentID]*document),
+		shares:    make(map[digiposte.ShareID]*share),
+		profile:   defaultProfile(),
+	}
+
+	server.API = httptest.NewServer(server.authenticated(server.apiHandler()))
+	server.Document = httptest.NewServer(server.documentHandler())
+
+	return server
+}
+
+// Close shuts down both servers.
+func (s *Server) Close() {
+	s.API.Close()
+	s.Document.Close()
+}
+
+// APIURL returns the URL to use as the API URL of a client.
+func (s *Server) APIURL() string {
+	return s.API.URL
+}
+
+// DocumentURL returns the URL to use as the document URL of a client.
+func (s *Server) DocumentURL() string {
+	return s.Document.URL
+}
+
+// LoginMethod returns a login method issuing a new token and session cookie on every call.
+// Credentials are ignored.
+func (s *Server) LoginMethod() login.Method { //nolint:ireturn
+	return login.MethodFunc(func(_ context.Context, _ *login.Credentials) (*oauth2.Token, []*http.Cookie, error) {
+		token := s.NewToken()
+
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		session := newID()
+		s.sessions[session] = struct{}{}
+
+		documentURL, err := url.Parse(s.Document.URL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse document URL: %w", err)
+		}
+
+		return token, []*http.Cookie{{
+			Name:     SessionCookieName,
+			Value:    session,
+			Path:     "/",
+			Domain:   documentURL.Hostname(),
+			HttpOnly: true,
+		}}, nil
+	})
+}
+
+// Config returns a client configuration pointing to the fake server.
+func (s *Server) Config() *digiposte.Config {
+	return &digiposte.Config{
+		APIURL:          s.APIURL(),
+		DocumentURL:     s.DocumentURL(),
+		LoginMethod:     s.LoginMethod(),
+		Credentials:     &login.Credentials{},
+		SessionListener: nil,
+		PreviousSession: nil,
+		SessionStore:    nil,
+	}
+}
+
+// NewToken issues a new valid access token.
+func (s *Server) NewToken() *oauth2.Token {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.newToken()
+}
+
+func (s *Server) newToken() *oauth2.Token {
+	token := &oauth2.Token{
+		AccessToken:  newID(),
+		TokenType:    "Bearer",
+		RefreshToken: "",
+		Expiry:       time.Now().Add(TokenLifetime),
+	}
+
+	s.tokens[token.AccessToken] = token.Expiry
+
+	return token
+}
+
+// isAuthenticated reports whether the request carries a valid bearer token or session cookie.
+// The caller must hold the lock.
+func (s *Server) isAuthenticated(req *http.Request) bool {
+	if bearer, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		if expiry, ok := s.tokens[bearer]; ok && time.Now().Before(expiry) {
+			return true
+		}
+	}
+
+	if cookie, err := req.Cookie(SessionCookieName); err == nil {
+		if _, ok := s.sessions[cookie.Value]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) authenticated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		s.lock.Lock()
+		ok := s.isAuthenticated(req)
+		s.lock.Unlock()
+
+		if !ok {
+			writeError(writer, http.StatusUnauthorized, ErrorCodeUnauthorized, "Full authentication is required.")
+
+			return
+		}
+
+		next.ServeHTTP(writer, req)
+	})
+}
+
+func (s *Server) apiHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/v3/documents", s.handleDocuments)
+	mux.HandleFunc("/v3/documents/search", s.handleSearchDocuments)
+	mux.HandleFunc("/v3/documents/copy", s.handleCopyDocuments)
+	mux.HandleFunc("/v3/documents/multiTag", s.handleMultiTag)
+	mux.HandleFunc("/v3/documents/multiUntag", s.handleMultiUntag)
+	mux.HandleFunc("/v3/documents/userTags", s.handleUserTags)
+	mux.HandleFunc("/v3/documents/favorite", s.handleDocumentsState(func(doc *document, favorite bool) {
+		doc.Favorite = favorite
+	}))
+	mux.HandleFunc("/v3/documents/read", s.handleDocumentsState(func(doc *document, _ bool) {
+		doc.Read = true
+	}))
+	mux.HandleFunc("/v3/documents/unread", s.handleDocumentsState(func(doc *document, _ bool) {
+		doc.Read = false
+	}))
+	mux.HandleFunc("/v3/document", s.handleCreateDocument)
+	mux.HandleFunc("/v3/document/", s.handleDocument)
+	mux.HandleFunc("/v3/folders", s.handleFolders)
+	mux.HandleFunc("/v3/folders/"+digiposte.TrashDirName, s.handleTrashedFolders)
+	mux.HandleFunc("/v3/folder", s.handleCreateFolder)
+	mux.HandleFunc("/v3/folder/", s.handleFolder)
+	mux.HandleFunc("/v3/file/tree/trash", s.handleTrash)
+	mux.HandleFunc("/v3/file/tree/untrash", s.handleUntrash)
+	mux.HandleFunc("/v3/file/tree/delete", s.handleDelete)
+	mux.HandleFunc("/v3/file/tree/move", s.handleMove)
+	mux.HandleFunc("/v3/share", s.handleCreateShare)
+	mux.HandleFunc("/v3/share/", s.handleShare)
+	mux.HandleFunc("/v3/shares/with_documents", s.handleSharesWithDocuments)
+	mux.HandleFunc("/v4/partner/user/shares", s.handleListShares)
+	mux.HandleFunc("/v4/profile", s.handleProfile)
+	mux.HandleFunc("/v4/profile/safe/size", s.handleProfileSafeSize)
+	mux.HandleFunc("/v3/profile/logout", s.handleLogout)
+
+	return mux
+}
+
+func (s *Server) documentHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/rest/content/document/", s.handleDocumentContent)
+	mux.HandleFunc("/rest/security/token", s.handleAccessToken)
+	mux.HandleFunc("/rest/security/app-token", s.handleAppToken)
+
+	return mux
+}
+
+func (s *Server) handleLogout(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodPost) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if bearer, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		delete(s.tokens, bearer)
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleAccessToken(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodGet) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.isAuthenticated(req) {
+		writeError(writer, http.StatusUnauthorized, ErrorCodeUnauthorized, "Full authentication is required.")
+
+		return
+	}
+
+	token := s.newToken()
+
+	writeJSON(writer, http.StatusOK, &digiposte.AccessToken{
+		Token:               token.AccessToken,
+		ExpiresAt:           token.Expiry,
+		IsTokenConsolidated: true,
+	})
+}
+
+func (s *Server) handleAppToken(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodGet) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.isAuthenticated(req) {
+		writeError(writer, http.StatusUnauthorized, ErrorCodeUnauthorized, "Full authentication is required.")
+
+		return
+	}
+
+	token := s.newToken()
+
+	writeJSON(writer, http.StatusOK, map[string]interface{}{
+		"app_access_token": token.AccessToken,
+		"app_expires_at":   float64(token.Expiry.UnixNano()) / float64(time.Second),
+	})
+}
+
+func allowMethod(writer http.ResponseWriter, req *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if req.Method == method {
+			return true
+		}
+	}
+
+	writer.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(writer, http.StatusMethodNotAllowed, ErrorCodeMethodNotAllow, req.Method+" is not supported.")
+
+	return false
+}
+
+func writeJSON(writer http.ResponseWriter, status int, body interface{}) {
+	writer.Header().Set("Content-Type", digiposte.JSONContentType)
+	writer.WriteHeader(status)
+
+	_ = json.NewEncoder(writer).Encode(body)
+}
+
+func writeError(writer http.ResponseWriter, status int, code, description string) {
+	writeJSON(writer, status, digiposte.RequestErrors{{
+		ErrorCode: code,
+		ErrorDesc: description,
+		Context:   nil,
+	}})
+}
+
+func readJSON(writer http.ResponseWriter, req *http.Request, body interface{}) bool {
+	if err := json.NewDecoder(req.Body).Decode(body); err != nil {
+		writeError(writer, http.StatusBadRequest, ErrorCodeBadRequest, "invalid body: "+err.Error())
+
+		return false
+	}
+
+	return true
+}
+
+// pathSegments returns the unescaped segments of the request path after the given prefix.
+func pathSegments(req *http.Request, prefix string) ([]string, bool) {
+	rest, ok := strings.CutPrefix(req.URL.EscapedPath(), prefix)
+	if !ok {
+		return nil, false
+	}
+
+	segments := strings.Split(rest, "/")
+
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, false
+		}
+
+		segments[i] = unescaped
+	}
+
+	return segments, true
+}
+
+func newID() string {
+	var buf [16]byte
+
+	if _, err := rand.Read(buf[:]); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(buf[:])
+}
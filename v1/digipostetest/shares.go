@@ -0,0 +1,381 @@
+package digipostetest
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"sort"
+	"time"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+type share struct {
+	digiposte.Share
+
+	documents []digiposte.DocumentID
+}
+
+// shareBody is the body sent to create a share.
+type shareBody struct {
+	Title        string `json:"title"`
+	StartDate    string `json:"start_date"`
+	EndDate      string `json:"end_date"`
+	SecurityCode string `json:"security_code"`
+}
+
+func parseShareDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse date: %w", err)
+	}
+
+	return date, nil
+}
+
+// parse validates the body and returns the dates of the share.
+// On failure, it writes the error and returns false.
+func (b *shareBody) parse(writer http.ResponseWriter) (time.Time, time.Time, bool) {
+	startDate, err := parseShareDate(b.StartDate)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, ErrorCodeBadRequest, "invalid start_date: "+err.Error())
+
+		return time.Time{}, time.Time{}, false
+	}
+
+	endDate, err := parseShareDate(b.EndDate)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, ErrorCodeBadRequest, "invalid end_date: "+err.Error())
+
+		return time.Time{}, time.Time{}, false
+	}
+
+	if !endDate.IsZero() && endDate.Before(startDate) {
+		writeError(writer, http.StatusBadRequest, ErrorCodeBadRequest, "end_date is before start_date.")
+
+		return time.Time{}, time.Time{}, false
+	}
+
+	if b.Title == "" {
+		writeError(writer, http.StatusBadRequest, ErrorCodeBadRequest, "title is required.")
+
+		return time.Time{}, time.Time{}, false
+	}
+
+	return startDate, endDate, true
+}
+
+func (s *Server) handleCreateShare(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodPost) {
+		return
+	}
+
+	body := new(shareBody)
+	if !readJSON(writer, req, body) {
+		return
+	}
+
+	startDate, endDate, ok := body.parse(writer)
+	if !ok {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	shortID := newID()[:8]
+
+	sh := &share{
+		Share: digiposte.Share{
+			InternalID:     digiposte.ShareID(newID()),
+			ShortID:        shortID,
+			SecurityCode:   body.SecurityCode,
+			ShortURL:       s.Document.URL + "/s/" + shortID,
+			Title:          body.Title,
+			StartDate:      startDate,
+			EndDate:        endDate,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			RecipientMails: []string{},
+		},
+		documents: []digiposte.DocumentID{},
+	}
+
+	s.shares[sh.InternalID] = sh
+
+	writeJSON(writer, http.StatusOK, &sh.Share)
+}
+
+// handleShare handles /v3/share/{id}, /v3/share/{id}/documents and /v3/share/{id}/recipients.
+func (s *Server) handleShare(writer http.ResponseWriter, req *http.Request) {
+	segments, ok := pathSegments(req, "/v3/share/")
+	if !ok || len(segments) > 2 || (len(segments) == 2 && segments[1] != "documents" && segments[1] != "recipients") {
+		writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Unknown endpoint.")
+
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sh, ok := s.shares[digiposte.ShareID(segments[0])]
+	if !ok {
+		writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Share not found.")
+
+		return
+	}
+
+	if len(segments) == 2 && segments[1] == "recipients" {
+		s.handleShareRecipients(writer, req, sh)
+
+		return
+	}
+
+	if len(segments) == 2 {
+		s.handleShareDocuments(writer, req, sh)
+
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		writeJSON(writer, http.StatusOK, &sh.Share)
+
+	case http.MethodPut:
+		body := new(shareBody)
+		if !readJSON(writer, req, body) {
+			return
+		}
+
+		startDate, endDate, ok := body.parse(writer)
+		if !ok {
+			return
+		}
+
+		sh.Title = body.Title
+		sh.StartDate = startDate
+		sh.EndDate = endDate
+		sh.SecurityCode = body.SecurityCode
+		sh.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+
+		writeJSON(writer, http.StatusOK, &sh.Share)
+
+	case http.MethodDelete:
+		delete(s.shares, sh.InternalID)
+		s.refreshShared()
+
+		writer.WriteHeader(http.StatusNoContent)
+
+	default:
+		allowMethod(writer, req, http.MethodGet, http.MethodPut, http.MethodDelete)
+	}
+}
+
+// handleShareRecipients handles /v3/share/{id}/recipients. The caller must hold the lock.
+func (s *Server) handleShareRecipients(writer http.ResponseWriter, req *http.Request, sh *share) {
+	if !allowMethod(writer, req, http.MethodPost) {
+		return
+	}
+
+	var body struct {
+		RecipientMails []string `json:"recipient_mails"`
+	}
+
+	if !readJSON(writer, req, &body) {
+		return
+	}
+
+	for _, address := range body.RecipientMails {
+		if _, err := mail.ParseAddress(address); err != nil {
+			writeError(writer, http.StatusBadRequest, ErrorCodeBadRequest, "invalid recipient "+address+": "+err.Error())
+
+			return
+		}
+	}
+
+	for _, address := range body.RecipientMails {
+		if !contains(sh.RecipientMails, address) {
+			sh.RecipientMails = append(sh.RecipientMails, address)
+		}
+	}
+
+	sh.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// handleShareDocuments handles /v3/share/{id}/documents. The caller must hold the lock.
+func (s *Server) handleShareDocuments(writer http.ResponseWriter, req *http.Request, sh *share) {
+	switch req.Method {
+	case http.MethodGet:
+		documents := s.shareDocuments(sh)
+
+		writeJSON(writer, http.StatusOK, &digiposte.SearchDocumentsResult{
+			Count:      int64(len(documents)),
+			Index:      0,
+			MaxResults: int64(len(documents)),
+			Documents:  documents,
+		})
+
+	case http.MethodPut:
+		var body struct {
+			IDs []digiposte.DocumentID `json:"ids"`
+		}
+
+		if !readJSON(writer, req, &body) {
+			return
+		}
+
+		for _, id := range body.IDs {
+			if _, ok := s.documents[id]; !ok {
+				writeError(writer, http.StatusNotFound, ErrorCodeNotFound, "Document "+string(id)+" not found.")
+
+				return
+			}
+		}
+
+		sh.documents = append([]digiposte.DocumentID{}, body.IDs...)
+		sh.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+		s.refreshShared()
+
+		writer.WriteHeader(http.StatusNoContent)
+
+	case http.MethodDelete:
+		var body struct {
+			IDs []digiposte.DocumentID `json:"ids"`
+		}
+
+		if !readJSON(writer, req, &body) {
+			return
+		}
+
+		for _, id := range body.IDs {
+			sh.documents = removeDocumentID(sh.documents, id)
+		}
+
+		sh.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+		s.refreshShared()
+
+		writer.WriteHeader(http.StatusNoContent)
+
+	default:
+		allowMethod(writer, req, http.MethodGet, http.MethodPut, http.MethodDelete)
+	}
+}
+
+// shareDocuments returns the documents of a share. The caller must hold the lock.
+func (s *Server) shareDocuments(sh *share) []*digiposte.Document {
+	documents := make([]*digiposte.Document, 0, len(sh.documents))
+
+	for _, id := range sh.documents {
+		if doc, ok := s.documents[id]; ok {
+			documents = append(documents, doc.snapshot())
+		}
+	}
+
+	return documents
+}
+
+// refreshShared updates the Shared flag of every document. The caller must hold the lock.
+func (s *Server) refreshShared() {
+	shared := make(map[digiposte.DocumentID]struct{})
+
+	for _, sh := range s.shares {
+		for _, id := range sh.documents {
+			shared[id] = struct{}{}
+		}
+	}
+
+	for id, doc := range s.documents {
+		_, doc.Shared = shared[id]
+	}
+}
+
+// sortedShares returns the shares ordered by creation date. The caller must hold the lock.
+func (s *Server) sortedShares() []*share {
+	shares := make([]*share, 0, len(s.shares))
+
+	for _, sh := range s.shares {
+		shares = append(shares, sh)
+	}
+
+	sort.Slice(shares, func(i, j int) bool {
+		if !shares[i].CreatedAt.Equal(shares[j].CreatedAt) {
+			return shares[i].CreatedAt.Before(shares[j].CreatedAt)
+		}
+
+		return shares[i].InternalID < shares[j].InternalID
+	})
+
+	return shares
+}
+
+func (s *Server) handleListShares(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodGet) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	result := &digiposte.ShareResult{
+		SenderShares: []digiposte.Share{},
+		ShareDatas:   []digiposte.Share{},
+	}
+
+	for _, sh := range s.sortedShares() {
+		result.SenderShares = append(result.SenderShares, sh.Share)
+	}
+
+	writeJSON(writer, http.StatusOK, result)
+}
+
+type shareWithDocuments struct {
+	ShareData digiposte.Share      `json:"share_data"`
+	Documents []digiposte.Document `json:"documents"`
+}
+
+func (s *Server) handleSharesWithDocuments(writer http.ResponseWriter, req *http.Request) {
+	if !allowMethod(writer, req, http.MethodGet) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	shares := make([]shareWithDocuments, 0, len(s.shares))
+
+	for _, sh := range s.sortedShares() {
+		documents := make([]digiposte.Document, 0, len(sh.documents))
+
+		for _, doc := range s.shareDocuments(sh) {
+			documents = append(documents, *doc)
+		}
+
+		shares = append(shares, shareWithDocuments{
+			ShareData: sh.Share,
+			Documents: documents,
+		})
+	}
+
+	writeJSON(writer, http.StatusOK, map[string]interface{}{
+		"share_data_and_documents": shares,
+	})
+}
+
+func removeDocumentID(ids []digiposte.DocumentID, id digiposte.DocumentID) []digiposte.DocumentID {
+	result := ids[:0]
+
+	for _, current := range ids {
+		if current != id {
+			result = append(result, current)
+		}
+	}
+
+	return result
+}
@@ -4,13 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"mime"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,28 +23,15 @@ type (
 	DocumentTag string
 )
 
-// GetTrashedDocuments returns all documents in the trash.
-func (c *Client) GetTrashedDocuments(ctx context.Context) (*SearchDocumentsResult, error) {
-	body, err := json.Marshal(map[string]interface{}{
+// GetTrashedDocuments returns the documents in the trash.
+// Only the first page is returned, use TrashedDocumentsIter to get all of them.
+func (c *Client) GetTrashedDocuments(ctx context.Context, options ...DocumentSearchOption) (
+	*SearchDocumentsResult,
+	error,
+) {
+	return c.searchDocuments(ctx, map[string]interface{}{
 		"user_removal": true,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("marshal body: %w", err)
-	}
-
-	req, err := c.apiRequest(ctx, http.MethodPost, "/v3/documents/search", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("new request: %w", err)
-	}
-
-	queryParams := req.URL.Query()
-	queryParams.Set("max_results", "1000")
-	queryParams.Set("sort", "TITLE")
-	req.URL.RawQuery = queryParams.Encode()
-
-	result := new(SearchDocumentsResult)
-
-	return result, c.call(req, result)
+	}, options...)
 }
 
 // Document represents a document.
@@ -56,6 +47,8 @@ type Document struct {
 	Read           bool       `json:"read"`
 	HealthDocument bool       `json:"health_document"`
 	UserTags       []string   `json:"user_tags"`
+	Favorite       bool       `json:"favorite"`
+	Certified      bool       `json:"certified"`
 }
 
 // ListDocuments returns all documents at the root.
@@ -79,58 +72,106 @@ func (e *RedirectionError) Error() string {
 }
 
 // DocumentContent returns the content of a document.
+// The whole content is buffered in memory, use DocumentStream for large documents.
 func (c *Client) DocumentContent(ctx context.Context, internalID DocumentID) ( //nolint:nonamedreturns
 	contentBuffer io.ReadCloser,
 	contentType string,
 	finalErr error,
+) {
+	stream, err := c.DocumentStream(ctx, internalID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer func() {
+		if err := stream.Close(); err != nil {
+			finalErr = &CloseBodyError{Err: err, OriginalError: finalErr}
+		}
+	}()
+
+	content, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, stream.ContentType, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return io.NopCloser(bytes.NewReader(content)), stream.ContentType, nil
+}
+
+// DocumentStreamReader is the content of a document, read directly from the server response.
+type DocumentStreamReader struct {
+	io.ReadCloser
+
+	// ContentType is the value of the Content-Type header.
+	ContentType string
+
+	// ContentLength is the size of the content, or -1 if unknown.
+	ContentLength int64
+
+	// Filename is the filename from the Content-Disposition header, if any.
+	Filename string
+}
+
+// DocumentStream returns the content of a document without buffering it.
+// The caller must close the returned reader.
+func (c *Client) DocumentStream(ctx context.Context, internalID DocumentID) ( //nolint:nonamedreturns
+	stream *DocumentStreamReader,
+	finalErr error,
 ) {
 	endpoint := c.documentURL + "/rest/content/document/" + url.PathEscape(string(internalID))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
-		return nil, "", fmt.Errorf("new request: %w", err)
+		return nil, fmt.Errorf("new request: %w", err)
 	}
 
-	response, err := c.client.Do(req)
+	response, err := c.do(req)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to request %q: %w", req.URL, err)
+		return nil, fmt.Errorf("failed to request %q: %w", req.URL, err)
 	}
 
 	defer func() {
+		if finalErr == nil {
+			return
+		}
+
 		if err := response.Body.Close(); err != nil {
 			finalErr = &CloseBodyError{Err: err, OriginalError: finalErr}
 		}
 	}()
 
-	contentType = response.Header.Get("Content-Type")
-
 	if response.StatusCode == http.StatusFound {
 		location, err := url.Parse(response.Header.Get("Location"))
 		if err != nil {
-			return nil, "", fmt.Errorf("parse location: %w", err)
+			return nil, fmt.Errorf("parse location: %w", err)
 		}
 
 		if strings.HasSuffix(location.Path, "/v3/authorize") {
-			return nil, "", &RequestErrors{{
+			return nil, newAPIError(req, http.StatusUnauthorized, &RequestErrors{{
 				ErrorCode: http.StatusText(http.StatusUnauthorized),
 				ErrorDesc: "Redirected to the login page.",
 				Context:   map[string]interface{}{"response": response},
-			}}
+			}})
 		}
 
-		return nil, "", &RedirectionError{Location: location.String()}
+		return nil, &RedirectionError{Location: location.String()}
 	}
 
 	if err := c.checkResponse(response, http.StatusOK); err != nil {
-		return nil, contentType, fmt.Errorf("request to %q: %w", req.URL, err)
+		return nil, err
 	}
 
-	content, err := io.ReadAll(response.Body)
-	if err != nil {
-		return nil, contentType, fmt.Errorf("failed to read response body: %w", err)
+	var filename string
+
+	if _, params, err := mime.ParseMediaType(response.Header.Get("Content-Disposition")); err == nil {
+		filename = params["filename"]
 	}
 
-	return io.NopCloser(bytes.NewReader(content)), contentType, nil
+	return &DocumentStreamReader{
+		ReadCloser:    response.Body,
+		ContentType:   response.Header.Get("Content-Type"),
+		ContentLength: response.ContentLength,
+		Filename:      filename,
+	}, nil
 }
 
 // SearchDocumentsResult represents the result of a search for documents.
@@ -245,20 +286,70 @@ func DocumentTaggedWith(tags ...DocumentTag) DocumentSearchOption {
 	}
 }
 
+const (
+	indexParam      = "index"
+	maxResultsParam = "max_results"
+)
+
+// DefaultPageSize is the number of documents returned by a search, unless WithPageSize is used.
+const DefaultPageSize int64 = 1000
+
+// WithPageSize sets the maximum number of documents returned by a single search request.
+func WithPageSize(size int64) DocumentSearchOption {
+	return func(body map[string]interface{}) {
+		body[maxResultsParam] = size
+	}
+}
+
+// FromIndex skips the given number of documents from the start of the search results.
+func FromIndex(index int64) DocumentSearchOption {
+	return func(body map[string]interface{}) {
+		body[indexParam] = index
+	}
+}
+
 // SearchDocuments searches for documents in the given locations.
+// Only the first page is returned, use SearchDocumentsIter to get all of them.
 func (c *Client) SearchDocuments(ctx context.Context, internalID FolderID, options ...DocumentSearchOption) (
 	*SearchDocumentsResult,
 	error,
 ) {
-	body := map[string]interface{}{
-		"folder_id": internalID,
-		"locations": []string{LocationInbox.String(), LocationSafe.String()},
-	}
+	return c.searchDocuments(ctx, map[string]interface{}{
+		folderIDParam: internalID,
+		"locations":   []string{LocationInbox.String(), LocationSafe.String()},
+	}, options...)
+}
+
+func (c *Client) searchDocuments(ctx context.Context, body map[string]interface{}, options ...DocumentSearchOption) (
+	*SearchDocumentsResult,
+	error,
+) {
+	body[maxResultsParam] = DefaultPageSize
+
+	body[sortParam] = SortByTitle
 
 	for _, option := range options {
 		option(body)
 	}
 
+	if err := validateSearch(body); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkFullContentSearch(ctx, body); err != nil {
+		return nil, err
+	}
+
+	queryParams := make(url.Values)
+
+	// Pagination and sort options are stored in the body by the options, but sent as query parameters.
+	for _, param := range []string{indexParam, maxResultsParam, sortParam, directionParam} {
+		if value, ok := body[param]; ok {
+			queryParams.Set(param, fmt.Sprint(value))
+			delete(body, param)
+		}
+	}
+
 	bodyBytes, err := json.Marshal(body)
 	if err != nil {
 		return nil, fmt.Errorf("marshal body: %w", err)
@@ -269,9 +360,8 @@ func (c *Client) SearchDocuments(ctx context.Context, internalID FolderID, optio
 		return nil, fmt.Errorf("new request: %w", err)
 	}
 
-	queryParams := req.URL.Query()
-	queryParams.Set("max_results", "1000")
-	queryParams.Set("sort", "TITLE")
+	req = markIdempotent(req)
+
 	req.URL.RawQuery = queryParams.Encode()
 
 	result := new(SearchDocumentsResult)
@@ -329,6 +419,43 @@ func (c *Client) MultiTag(ctx context.Context, tags map[DocumentID][]DocumentTag
 	return c.call(req, nil, http.StatusOK)
 }
 
+// SetFavorite adds the given documents to the favorites, or removes them.
+func (c *Client) SetFavorite(ctx context.Context, documentIDs []DocumentID, favorite bool) error {
+	return c.updateDocuments(ctx, "/v3/documents/favorite", map[string]interface{}{
+		"document_ids": documentIDs,
+		"favorite":     favorite,
+	})
+}
+
+// MarkRead marks the given documents as read.
+func (c *Client) MarkRead(ctx context.Context, documentIDs []DocumentID) error {
+	return c.updateDocuments(ctx, "/v3/documents/read", map[string]interface{}{
+		"document_ids": documentIDs,
+	})
+}
+
+// MarkUnread marks the given documents as unread.
+func (c *Client) MarkUnread(ctx context.Context, documentIDs []DocumentID) error {
+	return c.updateDocuments(ctx, "/v3/documents/unread", map[string]interface{}{
+		"document_ids": documentIDs,
+	})
+}
+
+// updateDocuments sends a request changing the state of documents, answered with no content.
+func (c *Client) updateDocuments(ctx context.Context, endpoint string, body map[string]interface{}) error {
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal body: %w", err)
+	}
+
+	req, err := c.apiRequest(ctx, http.MethodPost, endpoint, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+
+	return c.call(req, nil)
+}
+
 //go:generate stringer -type=DocumentType -trimprefix=DocumentType
 
 // DocumentType represents the type of a document.
@@ -341,7 +468,17 @@ const (
 	DocumentTypeHealth
 )
 
+// KnownSizeReader is a reader whose remaining size is known in advance.
+// Wrap the data given to CreateDocument with it when the size cannot be detected.
+type KnownSizeReader struct {
+	io.Reader
+
+	Size int64
+}
+
 // CreateDocument creates a document.
+// The content is streamed to the server. Its size is sent before the content when it can be
+// known in advance: *KnownSizeReader, *bytes.Reader, *strings.Reader, *os.File, *io.SectionReader...
 func (c *Client) CreateDocument( //nolint:nonamedreturns
 	ctx context.Context,
 	folderID FolderID,
@@ -349,69 +486,285 @@ func (c *Client) CreateDocument( //nolint:nonamedreturns
 	data io.Reader,
 	docType DocumentType,
 ) (document *Document, finalErr error) {
-	var buf bytes.Buffer
+	body := newUploadBody(docType, folderID, name, data)
 
-	formWriter, err := uploadForm(&buf, docType, folderID, name, data)
+	bodyReader, err := body.Open()
 	if err != nil {
-		return nil, fmt.Errorf("upload form: %w", err)
+		return nil, fmt.Errorf("open body: %w", err)
 	}
 
-	req, err := c.apiRequest(ctx, http.MethodPost, "/v3/document", &buf)
+	req, err := c.apiRequest(ctx, http.MethodPost, "/v3/document", bodyReader)
 	if err != nil {
+		body.Stop()
+
 		return nil, fmt.Errorf("new request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", formWriter.FormDataContentType())
+	if body.Replayable() {
+		req.GetBody = body.Open
+	}
+
+	req.Header.Set("Content-Type", body.ContentType())
 	req.Header.Set("X-API-VERSION-MINOR", "2")
 	req.Header.Set("Origin", "https://github.com/holyhope/digiposte-go-sdk")
 
 	document = new(Document)
 
-	return document, c.call(req, document)
+	err = c.call(req, document)
+
+	if uploadErr := body.Stop(); uploadErr != nil {
+		return nil, &UploadError{Err: uploadErr, OriginalError: err}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+var (
+	errRequestDone   = errors.New("request done")
+	errSizeMismatch  = errors.New("size mismatch")
+	errNotReplayable = errors.New("upload data cannot be replayed")
+)
+
+// uploadBody produces the multipart form of an upload through a pipe.
+// It can be opened again to replay the upload, as long as the data is seekable.
+type uploadBody struct {
+	docType  DocumentType
+	folderID FolderID
+	name     string
+	data     io.Reader
+
+	boundary    string
+	contentType string
+	offset      int64
+
+	lock   sync.Mutex
+	reader *io.PipeReader
+	errs   chan error
+}
+
+func newUploadBody(docType DocumentType, folderID FolderID, name string, data io.Reader) *uploadBody {
+	formWriter := multipart.NewWriter(io.Discard)
+
+	offset := int64(-1)
+
+	if seeker, ok := data.(io.Seeker); ok {
+		if current, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			offset = current
+		}
+	}
+
+	return &uploadBody{
+		docType:     docType,
+		folderID:    folderID,
+		name:        name,
+		data:        data,
+		boundary:    formWriter.Boundary(),
+		contentType: formWriter.FormDataContentType(),
+		offset:      offset,
+		lock:        sync.Mutex{},
+		reader:      nil,
+		errs:        nil,
+	}
+}
+
+// ContentType returns the Content-Type header of the form, the same for every replay.
+func (b *uploadBody) ContentType() string {
+	return b.contentType
+}
+
+// Replayable reports whether the body can be opened more than once.
+func (b *uploadBody) Replayable() bool {
+	return b.offset >= 0
+}
+
+// Open stops the previous production of the form, if any, and starts a new one.
+func (b *uploadBody) Open() (io.ReadCloser, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.reader != nil {
+		_ = b.stop()
+
+		if !b.Replayable() {
+			return nil, errNotReplayable
+		}
+
+		if _, err := b.data.(io.Seeker).Seek(b.offset, io.SeekStart); err != nil { //nolint:forcetypeassert
+			return nil, fmt.Errorf("seek data: %w", err)
+		}
+	}
+
+	reader, writer := io.Pipe()
+
+	formWriter := multipart.NewWriter(writer)
+	if err := formWriter.SetBoundary(b.boundary); err != nil {
+		return nil, fmt.Errorf("set boundary: %w", err)
+	}
+
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- uploadForm(writer, formWriter, b.docType, b.folderID, b.name, b.data)
+	}()
+
+	b.reader, b.errs = reader, errs
+
+	return reader, nil
+}
+
+// Stop interrupts the current production of the form, and returns its error if any.
+func (b *uploadBody) Stop() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.stop()
+}
+
+func (b *uploadBody) stop() error {
+	if b.reader == nil {
+		return nil
+	}
+
+	// Unblock the upload if the request ended before the whole form was sent.
+	b.reader.CloseWithError(errRequestDone)
+
+	err := <-b.errs
+
+	b.reader, b.errs = nil, nil
+
+	// The pipe is closed by the transport or by stop when the request ends early.
+	if errors.Is(err, errRequestDone) || errors.Is(err, io.ErrClosedPipe) {
+		return nil
+	}
+
+	return err
 }
 
+// uploadForm writes the multipart form to the pipe, then closes it.
+// On failure, the pipe is closed with the error so that the request fails too.
 func uploadForm(
-	writer io.Writer,
+	writer *io.PipeWriter,
+	formWriter *multipart.Writer,
 	docType DocumentType,
 	folderID FolderID,
 	name string,
 	data io.Reader,
-) (*multipart.Writer, error) {
-	formWriter := multipart.NewWriter(writer)
+) error {
+	err := writeUploadForm(formWriter, docType, folderID, name, data)
+	if err == nil {
+		if closeErr := formWriter.Close(); closeErr != nil {
+			err = &CloseWriterError{Err: fmt.Errorf("close form writer: %w", closeErr), OriginalError: nil}
+		}
+	}
+
+	if err != nil {
+		writer.CloseWithError(err)
+
+		return err
+	}
 
+	if err := writer.Close(); err != nil {
+		return &CloseWriterError{Err: fmt.Errorf("close pipe: %w", err), OriginalError: nil}
+	}
+
+	return nil
+}
+
+func writeUploadForm(
+	formWriter *multipart.Writer,
+	docType DocumentType,
+	folderID FolderID,
+	name string,
+	data io.Reader,
+) error {
 	if err := formWriter.WriteField("health_document", strconv.FormatBool(docType == DocumentTypeHealth)); err != nil {
-		return formWriter, fmt.Errorf("write health_document: %w", err)
+		return fmt.Errorf("write health_document: %w", err)
 	}
 
 	if folderID != "" {
 		if err := formWriter.WriteField("folder_id", string(folderID)); err != nil {
-			return formWriter, fmt.Errorf("write folder_id: %w", err)
+			return fmt.Errorf("write folder_id: %w", err)
 		}
 	}
 
 	if err := formWriter.WriteField("title", name); err != nil {
-		return formWriter, fmt.Errorf("write title: %w", err)
+		return fmt.Errorf("write title: %w", err)
+	}
+
+	expectedSize, sizeKnown := uploadSize(data)
+	if sizeKnown {
+		if err := formWriter.WriteField("archive_size", strconv.FormatInt(expectedSize, 10)); err != nil {
+			return fmt.Errorf("write archive_size: %w", err)
+		}
 	}
 
 	documentUploadStream, err := formWriter.CreateFormFile("archive", name)
 	if err != nil {
-		return formWriter, fmt.Errorf("create archive file: %w", err)
+		return fmt.Errorf("create archive file: %w", err)
 	}
 
 	size, err := io.Copy(documentUploadStream, data)
 	if err != nil {
-		return formWriter, fmt.Errorf("copy archive file: %w", err)
+		return fmt.Errorf("copy archive file: %w", err)
+	}
+
+	if sizeKnown {
+		if size != expectedSize {
+			return fmt.Errorf("%w: read %d bytes, expected %d", errSizeMismatch, size, expectedSize)
+		}
+
+		return nil
 	}
 
 	if err := formWriter.WriteField("archive_size", strconv.FormatInt(size, 10)); err != nil {
-		return formWriter, fmt.Errorf("write archive_size: %w", err)
+		return fmt.Errorf("write archive_size: %w", err)
 	}
 
-	if err := formWriter.Close(); err != nil {
-		return formWriter, fmt.Errorf("close form writer: %w", err)
+	return nil
+}
+
+// uploadSize returns the remaining size of the data, if it can be known without reading it.
+func uploadSize(data io.Reader) (int64, bool) {
+	switch data := data.(type) {
+	case *KnownSizeReader:
+		return data.Size, true
+
+	case interface{ Len() int }: // *bytes.Reader, *strings.Reader, *bytes.Buffer
+		return int64(data.Len()), true
+
+	case interface{ Stat() (fs.FileInfo, error) }: // *os.File, fs.File
+		stat, err := data.Stat()
+		if err != nil || !stat.Mode().IsRegular() {
+			return 0, false
+		}
+
+		return remainingSize(data, stat.Size())
+
+	case interface{ Size() int64 }: // *io.SectionReader
+		return remainingSize(data, data.Size())
+
+	default:
+		return 0, false
+	}
+}
+
+// remainingSize subtracts the current offset of the data from its total size.
+func remainingSize(data interface{}, size int64) (int64, bool) {
+	seeker, ok := data.(io.Seeker)
+	if !ok {
+		return size, true
+	}
+
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, false
 	}
 
-	return formWriter, nil
+	return size - offset, true
 }
 
 type UserTags struct {
@@ -1,10 +1,13 @@
 package digiposte_test
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"mime"
 	"net/http"
 	"strings"
+	"testing/iotest"
 
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
@@ -12,6 +15,7 @@ import (
 	"github.com/onsi/gomega/gstruct"
 
 	"github.com/holyhope/digiposte-go-sdk/v1"
+	"github.com/holyhope/digiposte-go-sdk/v1/digipostetest"
 )
 
 var _ = ginkgo.Describe("Document", func() {
@@ -46,6 +50,50 @@ var _ = ginkgo.Describe("Document", func() {
 				gomega.Expect(document.InternalID).ToNot(gomega.BeEmpty())
 			})
 		})
+
+		ginkgo.Context("When the size of the content is unknown", func() {
+			ginkgo.It("Should create a document", func(ctx ginkgo.SpecContext) {
+				var err error
+
+				document, err = digiposteClient.CreateDocument(ctx,
+					digiposte.RootFolderID,
+					ginkgo.CurrentSpecReport().FullText(),
+					io.MultiReader(strings.NewReader("the "), strings.NewReader("content")),
+					digiposte.DocumentTypeBasic,
+				)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+				gomega.Expect(document.Size).To(gomega.BeEquivalentTo(len("the content")))
+			})
+		})
+
+		ginkgo.Context("When the content cannot be read", func() {
+			ginkgo.It("Should return an upload error", func(ctx ginkgo.SpecContext) {
+				var err error
+
+				document, err = digiposteClient.CreateDocument(ctx,
+					digiposte.RootFolderID,
+					ginkgo.CurrentSpecReport().FullText(),
+					iotest.ErrReader(io.ErrUnexpectedEOF),
+					digiposte.DocumentTypeBasic,
+				)
+				gomega.Expect(err).To(gomega.BeAssignableToTypeOf(&digiposte.UploadError{}))
+				gomega.Expect(err).To(gomega.MatchError(io.ErrUnexpectedEOF))
+			})
+		})
+
+		ginkgo.Context("When the known size is wrong", func() {
+			ginkgo.It("Should return an upload error", func(ctx ginkgo.SpecContext) {
+				var err error
+
+				document, err = digiposteClient.CreateDocument(ctx,
+					digiposte.RootFolderID,
+					ginkgo.CurrentSpecReport().FullText(),
+					&digiposte.KnownSizeReader{Reader: strings.NewReader("the content"), Size: 3},
+					digiposte.DocumentTypeBasic,
+				)
+				gomega.Expect(err).To(gomega.BeAssignableToTypeOf(&digiposte.UploadError{}))
+			})
+		})
 	})
 
 	ginkgo.Describe("TagDocument", func() {
@@ -107,6 +155,95 @@ var _ = ginkgo.Describe("Document", func() {
 		})
 	})
 
+	ginkgo.Describe("Document state", func() {
+		ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+			var err error
+
+			document, err = digiposteClient.CreateDocument(ctx,
+				digiposte.RootFolderID,
+				ginkgo.CurrentSpecReport().FullText(),
+				strings.NewReader("the content"),
+				digiposte.DocumentTypeBasic,
+			)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		})
+
+		ginkgo.AfterEach(func(ctx ginkgo.SpecContext) {
+			if err := digiposteClient.Trash(ctx, []digiposte.DocumentID{document.InternalID}, nil); err != nil {
+				fmt.Fprintf(ginkgo.GinkgoWriter, "trash: %v\n", err)
+			}
+
+			if err := digiposteClient.Delete(ctx, []digiposte.DocumentID{document.InternalID}, nil); err != nil {
+				fmt.Fprintf(ginkgo.GinkgoWriter, "delete: %v\n", err)
+			}
+		})
+
+		search := func(ctx ginkgo.SpecContext, options ...digiposte.DocumentSearchOption) []*digiposte.Document {
+			result, err := digiposteClient.SearchDocuments(ctx, digiposte.RootFolderID, options...)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			var found []*digiposte.Document
+
+			for _, doc := range result.Documents {
+				if doc.InternalID == document.InternalID {
+					found = append(found, doc)
+				}
+			}
+
+			return found
+		}
+
+		ginkgo.It("Should set the favorite flag", func(ctx ginkgo.SpecContext) {
+			ids := []digiposte.DocumentID{document.InternalID}
+
+			gomega.Expect(digiposteClient.SetFavorite(ctx, ids, true)).To(gomega.Succeed())
+			gomega.Expect(search(ctx, digiposte.FavoriteDocuments())).To(gomega.ConsistOf(gstruct.PointTo(
+				gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{"Favorite": gomega.BeTrue()}),
+			)))
+
+			gomega.Expect(digiposteClient.SetFavorite(ctx, ids, false)).To(gomega.Succeed())
+			gomega.Expect(search(ctx, digiposte.FavoriteDocuments())).To(gomega.BeEmpty())
+			gomega.Expect(search(ctx, digiposte.NotFavoriteDocuments())).To(gomega.HaveLen(1))
+		})
+
+		ginkgo.It("Should mark the document as read and unread", func(ctx ginkgo.SpecContext) {
+			ids := []digiposte.DocumentID{document.InternalID}
+
+			gomega.Expect(digiposteClient.MarkRead(ctx, ids)).To(gomega.Succeed())
+			gomega.Expect(search(ctx, digiposte.ReadDocuments())).To(gomega.ConsistOf(gstruct.PointTo(
+				gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{"Read": gomega.BeTrue()}),
+			)))
+
+			gomega.Expect(digiposteClient.MarkUnread(ctx, ids)).To(gomega.Succeed())
+			gomega.Expect(search(ctx, digiposte.ReadDocuments())).To(gomega.BeEmpty())
+		})
+
+		ginkgo.It("Should decode the certified flag", func(ctx ginkgo.SpecContext) {
+			server := digipostetest.NewServer()
+			ginkgo.DeferCleanup(server.Close)
+
+			client, err := digiposte.NewAuthenticatedClient(ctx, new(http.Client), server.Config())
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			certified := server.AddDocument(digiposte.RootFolderID, "payslip.pdf", []byte("content"), digiposte.LocationSafe)
+			server.CertifyDocument(certified.InternalID, true)
+
+			result, err := client.SearchDocuments(ctx, digiposte.RootFolderID, digiposte.CertifiedDocuments())
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(result.Documents).To(gomega.ConsistOf(gstruct.PointTo(
+				gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+					"InternalID": gomega.Equal(certified.InternalID),
+					"Certified":  gomega.BeTrue(),
+				}),
+			)))
+		})
+
+		ginkgo.It("Should fail for a missing document", func(ctx ginkgo.SpecContext) {
+			gomega.Expect(digiposteClient.MarkRead(ctx, []digiposte.DocumentID{"missing"})).
+				To(gomega.MatchError(digiposte.ErrNotFound))
+		})
+	})
+
 	ginkgo.Describe("DocumentContent", func() {
 		ginkgo.Context("When the document does not exist", func() {
 			ginkgo.BeforeEach(func() {
@@ -152,17 +289,33 @@ var _ = ginkgo.Describe("Document", func() {
 				))
 			})
 
+			ginkgo.It("Should stream the content with its metadata", func(ctx ginkgo.SpecContext) {
+				stream, err := digiposteClient.DocumentStream(ctx, document.InternalID)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+				defer func() {
+					gomega.Expect(stream.Close()).To(gomega.Succeed())
+				}()
+
+				gomega.Expect(stream.ContentLength).To(gomega.BeEquivalentTo(len("the content")))
+				gomega.Expect(stream.Filename).To(gomega.Equal(document.Name))
+				gomega.Eventually(gbytes.BufferReader(stream)).Should(gbytes.Say("^the content$"))
+			})
+
 			ginkgo.Context("With a non-authenticated client", func() {
 				var client *digiposte.Client
 
 				ginkgo.BeforeEach(func() {
-					client = digiposte.NewClient(http.DefaultClient)
+					client = digiposte.NewCustomClient(apiURL, documentURL, http.DefaultClient)
 				})
 
 				ginkgo.It("Should return an error", func(ctx ginkgo.SpecContext) {
 					_, _, err := client.DocumentContent(ctx, document.InternalID)
-					gomega.Expect(err).To(gomega.HaveOccurred())
-					gomega.Expect(err).To(gstruct.PointTo(gomega.ContainElement(
+					gomega.Expect(err).To(gomega.MatchError(digiposte.ErrUnauthorized))
+
+					var requestErrs *digiposte.RequestErrors
+					gomega.Expect(errors.As(err, &requestErrs)).To(gomega.BeTrue())
+					gomega.Expect(requestErrs).To(gstruct.PointTo(gomega.ContainElement(
 						gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
 							"ErrorCode": gomega.Equal("Unauthorized"),
 							"ErrorDesc": gomega.Equal("Redirected to the login page."),
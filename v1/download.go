@@ -0,0 +1,373 @@
+package digiposte
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/holyhope/digiposte-go-sdk/internal/utils"
+)
+
+// DefaultDownloadWorkers is the number of documents downloaded at the same time, unless WithDownloadWorkers is used.
+const DefaultDownloadWorkers = 4
+
+// downloadTempPrefix is the prefix of the temporary files, renamed once the download is complete.
+const downloadTempPrefix = ".digiposte-download-"
+
+// DocumentSource is a stream of documents, such as a *DocumentIterator.
+type DocumentSource interface {
+	Next() bool
+	Document() *Document
+	Err() error
+}
+
+var _ DocumentSource = (*DocumentIterator)(nil)
+
+// DocumentsOf returns a source over the given documents, such as the Documents of a SearchDocumentsResult.
+func DocumentsOf(documents ...*Document) DocumentSource { //nolint:ireturn
+	return &documentSlice{documents: documents, index: -1}
+}
+
+type documentSlice struct {
+	documents []*Document
+	index     int
+}
+
+func (s *documentSlice) Next() bool {
+	s.index++
+
+	return s.index < len(s.documents)
+}
+
+func (s *documentSlice) Document() *Document {
+	return s.documents[s.index]
+}
+
+func (s *documentSlice) Err() error {
+	return nil
+}
+
+// DownloadEventKind is the kind of a DownloadEvent.
+type DownloadEventKind int
+
+const (
+	// DownloadStarted is sent before the content of a document is requested.
+	DownloadStarted DownloadEventKind = iota
+	// DownloadProgressed is sent after some bytes of a document were written.
+	DownloadProgressed
+	// DownloadDone is sent once a document is written to its final path.
+	DownloadDone
+	// DownloadSkipped is sent when a file with the length of the content already exists.
+	DownloadSkipped
+	// DownloadFailed is sent when a document could not be downloaded.
+	DownloadFailed
+)
+
+// DownloadStats are the totals of a download.
+type DownloadStats struct {
+	// Documents is the number of documents written.
+	Documents int
+	// Skipped is the number of documents whose file already existed.
+	Skipped int
+	// Failed is the number of documents that could not be downloaded.
+	Failed int
+	// Bytes is the number of bytes written.
+	Bytes int64
+}
+
+// DownloadEvent reports the progress of a download.
+type DownloadEvent struct {
+	Kind     DownloadEventKind
+	Document *Document
+	// Path is the path of the file of the document, relative to the destination.
+	Path string
+	// Bytes is the number of bytes written by a DownloadProgressed event.
+	Bytes int64
+	// Err is the failure of a DownloadFailed event.
+	Err error
+	// Stats are the totals so far, including this event.
+	Stats DownloadStats
+}
+
+// DownloadError is the failure of the download of a document.
+type DownloadError struct {
+	Document *Document
+	Path     string
+	Err      error
+}
+
+func (e *DownloadError) Error() string {
+	return fmt.Sprintf("download %s to %q: %v", e.Document.InternalID, e.Path, e.Err)
+}
+
+func (e *DownloadError) Unwrap() error {
+	return e.Err
+}
+
+// Downloader writes documents to a local directory, with several workers.
+type Downloader struct {
+	client   *Client
+	dir      string
+	workers  int
+	path     func(*Document) string
+	progress func(DownloadEvent)
+}
+
+// DownloaderOption configures a Downloader.
+type DownloaderOption func(*Downloader)
+
+// WithDownloadWorkers sets the number of documents downloaded at the same time. The default is DefaultDownloadWorkers.
+func WithDownloadWorkers(workers int) DownloaderOption {
+	return func(d *Downloader) {
+		d.workers = workers
+	}
+}
+
+// WithDocumentPath sets the path of the file of each document, relative to the destination.
+// By default, all the documents are written in the destination, named after the document.
+// Names used by several documents of the same download get the ID of the document as a suffix.
+func WithDocumentPath(documentPath func(*Document) string) DownloaderOption {
+	return func(d *Downloader) {
+		d.path = documentPath
+	}
+}
+
+// WithDownloadProgress sets a function receiving the progress events.
+// The events are sent one at a time, even with several workers.
+func WithDownloadProgress(progress func(DownloadEvent)) DownloaderOption {
+	return func(d *Downloader) {
+		d.progress = progress
+	}
+}
+
+// NewDownloader returns a Downloader writing to the given directory.
+func (c *Client) NewDownloader(dir string, options ...DownloaderOption) *Downloader {
+	downloader := &Downloader{
+		client:   c,
+		dir:      dir,
+		workers:  DefaultDownloadWorkers,
+		path:     func(document *Document) string { return SanitizeName(document.Name) },
+		progress: func(DownloadEvent) {},
+	}
+
+	for _, option := range options {
+		option(downloader)
+	}
+
+	if downloader.workers < 1 {
+		downloader.workers = 1
+	}
+
+	return downloader
+}
+
+// Download downloads all the documents of the source.
+// Each file is written to a temporary file then renamed, and its modification time is set to
+// the creation date of the document. Files whose size already matches the length of the content are skipped.
+// The failed documents do not stop the others: their errors are joined as *DownloadError.
+func (d *Downloader) Download(ctx context.Context, source DocumentSource) (*DownloadStats, error) {
+	run := &downloadRun{
+		downloader: d,
+		lock:       sync.Mutex{},
+		stats:      DownloadStats{Documents: 0, Skipped: 0, Failed: 0, Bytes: 0},
+		paths:      make(map[string]DocumentID),
+		errs:       nil,
+	}
+
+	jobs := make(chan downloadJob)
+
+	var workers sync.WaitGroup
+
+	for i := 0; i < d.workers; i++ {
+		workers.Add(1)
+
+		go func() {
+			defer workers.Done()
+
+			for job := range jobs {
+				run.download(ctx, job)
+			}
+		}()
+	}
+
+	run.dispatch(ctx, source, jobs)
+
+	close(jobs)
+	workers.Wait()
+
+	if err := source.Err(); err != nil {
+		run.errs = append(run.errs, fmt.Errorf("list documents: %w", err))
+	}
+
+	stats := run.stats
+
+	return &stats, errors.Join(run.errs...)
+}
+
+type downloadJob struct {
+	document *Document
+	path     string
+}
+
+type downloadRun struct {
+	downloader *Downloader
+
+	// lock protects the fields below, and serializes the progress events.
+	lock  sync.Mutex
+	stats DownloadStats
+	paths map[string]DocumentID
+	errs  []error
+}
+
+// dispatch sends the documents of the source to the workers, until the source or the context is done.
+func (r *downloadRun) dispatch(ctx context.Context, source DocumentSource, jobs chan<- downloadJob) {
+	for source.Next() {
+		document := source.Document()
+
+		job := downloadJob{document: document, path: r.claim(document)}
+
+		select {
+		case jobs <- job:
+		case <-ctx.Done():
+			r.lock.Lock()
+			r.errs = append(r.errs, ctx.Err()) //nolint:wrapcheck
+			r.lock.Unlock()
+
+			return
+		}
+	}
+}
+
+// claim returns the path of the document, made unique among the documents of the download.
+func (r *downloadRun) claim(document *Document) string {
+	name := filepath.ToSlash(r.downloader.path(document))
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if owner, ok := r.paths[name]; ok && owner != document.InternalID {
+		ext := path.Ext(name)
+		if ext == path.Base(name) {
+			ext = ""
+		}
+
+		name = strings.TrimSuffix(name, ext) + "~" + string(document.InternalID) + ext
+	}
+
+	r.paths[name] = document.InternalID
+
+	return name
+}
+
+func (r *downloadRun) download(ctx context.Context, job downloadJob) {
+	r.send(DownloadStarted, job, 0, nil)
+
+	skipped, err := r.write(ctx, job)
+
+	switch {
+	case err != nil:
+		r.send(DownloadFailed, job, 0, &DownloadError{Document: job.document, Path: job.path, Err: err})
+	case skipped:
+		r.send(DownloadSkipped, job, 0, nil)
+	default:
+		r.send(DownloadDone, job, 0, nil)
+	}
+}
+
+// write writes the document to its file, unless a file has the length of its content.
+// The length is the one answered with the content, as the size reported in the document can differ.
+// The download is not skipped when the length is unknown.
+func (r *downloadRun) write(ctx context.Context, job downloadJob) (_ bool, finalErr error) { //nolint:nonamedreturns
+	if !filepath.IsLocal(filepath.FromSlash(job.path)) {
+		return false, fmt.Errorf("%w: the path is outside of the destination", ErrValidation)
+	}
+
+	target := filepath.Join(r.downloader.dir, filepath.FromSlash(job.path))
+
+	stream, err := r.downloader.client.DocumentStream(ctx, job.document.InternalID)
+	if err != nil {
+		return false, err
+	}
+
+	defer func() {
+		if err := stream.Close(); err != nil && finalErr == nil {
+			finalErr = fmt.Errorf("close stream: %w", err)
+		}
+	}()
+
+	info, err := os.Stat(target)
+	if err == nil && info.Mode().IsRegular() && stream.ContentLength >= 0 && info.Size() == stream.ContentLength {
+		return true, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return false, fmt.Errorf("create directory: %w", err)
+	}
+
+	return false, r.writeFile(job, target, stream)
+}
+
+func (r *downloadRun) writeFile(job downloadJob, target string, stream io.Reader) error {
+	err := utils.WriteFile(target, downloadTempPrefix+"*", job.document.CreatedAt, func(w io.Writer) error {
+		if _, err := io.Copy(w, &progressReader{reader: stream, run: r, job: job}); err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+// send updates the stats with the event, then sends it.
+func (r *downloadRun) send(kind DownloadEventKind, job downloadJob, bytes int64, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	switch kind {
+	case DownloadStarted:
+	case DownloadProgressed:
+		r.stats.Bytes += bytes
+	case DownloadDone:
+		r.stats.Documents++
+	case DownloadSkipped:
+		r.stats.Skipped++
+	case DownloadFailed:
+		r.stats.Failed++
+		r.errs = append(r.errs, err)
+	}
+
+	r.downloader.progress(DownloadEvent{
+		Kind:     kind,
+		Document: job.document,
+		Path:     job.path,
+		Bytes:    bytes,
+		Err:      err,
+		Stats:    r.stats,
+	})
+}
+
+// progressReader sends a DownloadProgressed event for each read.
+type progressReader struct {
+	reader io.Reader
+	run    *downloadRun
+	job    downloadJob
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.run.send(DownloadProgressed, r.job, int64(n), nil)
+	}
+
+	return n, err //nolint:wrapcheck
+}
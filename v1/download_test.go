@@ -0,0 +1,125 @@
+package digiposte_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+	"github.com/holyhope/digiposte-go-sdk/v1/digipostetest"
+)
+
+var _ = ginkgo.Describe("Downloader", func() {
+	var (
+		client    *digiposte.Client
+		dir       string
+		documents []*digiposte.Document
+	)
+
+	ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+		server := digipostetest.NewServer()
+		ginkgo.DeferCleanup(server.Close)
+
+		var err error
+
+		client, err = digiposte.NewAuthenticatedClient(ctx, new(http.Client), server.Config())
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		dir = ginkgo.GinkgoT().TempDir()
+
+		documents = []*digiposte.Document{
+			server.AddDocument(digiposte.RootFolderID, "avis.pdf", []byte("first avis"), digiposte.LocationSafe),
+			server.AddDocument(digiposte.RootFolderID, "avis.pdf", []byte("second avis"), digiposte.LocationSafe),
+			server.AddDocument(digiposte.RootFolderID, "facture.txt", []byte("facture"), digiposte.LocationSafe),
+		}
+	})
+
+	ginkgo.It("Should download the documents with progress events", func(ctx ginkgo.SpecContext) {
+		var (
+			lock   sync.Mutex
+			events = make(map[digiposte.DownloadEventKind]int)
+		)
+
+		downloader := client.NewDownloader(dir, digiposte.WithDownloadWorkers(2),
+			digiposte.WithDownloadProgress(func(event digiposte.DownloadEvent) {
+				lock.Lock()
+				defer lock.Unlock()
+
+				events[event.Kind]++
+			}))
+
+		stats, err := downloader.Download(ctx, digiposte.DocumentsOf(documents...))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(*stats).To(gomega.Equal(digiposte.DownloadStats{Documents: 3, Skipped: 0, Failed: 0, Bytes: 28}))
+		gomega.Expect(events).To(gomega.HaveKeyWithValue(digiposte.DownloadStarted, 3))
+		gomega.Expect(events).To(gomega.HaveKeyWithValue(digiposte.DownloadDone, 3))
+
+		gomega.Expect(os.ReadFile(filepath.Join(dir, "avis.pdf"))).To(gomega.Equal([]byte("first avis")))
+		gomega.Expect(os.ReadFile(filepath.Join(dir, "avis~"+string(documents[1].InternalID)+".pdf"))).
+			To(gomega.Equal([]byte("second avis")))
+
+		info, err := os.Stat(filepath.Join(dir, "facture.txt"))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(info.ModTime()).To(gomega.BeTemporally("==", documents[2].CreatedAt))
+
+		entries, err := os.ReadDir(dir)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(entries).To(gomega.HaveLen(3), "no temporary file is left")
+	})
+
+	ginkgo.It("Should skip the files of the same size", func(ctx ginkgo.SpecContext) {
+		gomega.Expect(os.WriteFile(filepath.Join(dir, "facture.txt"), []byte("FACTURE"), 0o600)).To(gomega.Succeed())
+
+		stats, err := client.NewDownloader(dir).Download(ctx, digiposte.DocumentsOf(documents[2]))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(stats.Skipped).To(gomega.Equal(1))
+
+		gomega.Expect(os.ReadFile(filepath.Join(dir, "facture.txt"))).To(gomega.Equal([]byte("FACTURE")))
+	})
+
+	ginkgo.It("Should compare the files with the length of the content", func(ctx ginkgo.SpecContext) {
+		wrongSize := *documents[2]
+		wrongSize.Size = 3
+
+		gomega.Expect(os.WriteFile(filepath.Join(dir, "facture.txt"), []byte("fac"), 0o600)).To(gomega.Succeed())
+
+		stats, err := client.NewDownloader(dir).Download(ctx, digiposte.DocumentsOf(&wrongSize))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(stats.Documents).To(gomega.Equal(1))
+		gomega.Expect(os.ReadFile(filepath.Join(dir, "facture.txt"))).To(gomega.Equal([]byte("facture")))
+
+		stats, err = client.NewDownloader(dir).Download(ctx, digiposte.DocumentsOf(&wrongSize))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(stats.Skipped).To(gomega.Equal(1))
+	})
+
+	ginkgo.It("Should report the failed documents without stopping", func(ctx ginkgo.SpecContext) {
+		missing := *documents[0]
+		missing.InternalID = "missing"
+		missing.Name = "missing.pdf"
+
+		stats, err := client.NewDownloader(dir).Download(ctx, digiposte.DocumentsOf(&missing, documents[2]))
+		gomega.Expect(err).To(gomega.MatchError(digiposte.ErrNotFound))
+
+		var downloadErr *digiposte.DownloadError
+
+		gomega.Expect(errors.As(err, &downloadErr)).To(gomega.BeTrue())
+		gomega.Expect(downloadErr.Path).To(gomega.Equal("missing.pdf"))
+		gomega.Expect(*stats).To(gomega.Equal(digiposte.DownloadStats{Documents: 1, Skipped: 0, Failed: 1, Bytes: 7}))
+		gomega.Expect(filepath.Join(dir, "missing.pdf")).ToNot(gomega.BeAnExistingFile())
+	})
+
+	ginkgo.It("Should keep the files in the destination", func(ctx ginkgo.SpecContext) {
+		downloader := client.NewDownloader(dir, digiposte.WithDocumentPath(func(*digiposte.Document) string {
+			return "../outside"
+		}))
+
+		_, err := downloader.Download(ctx, digiposte.DocumentsOf(documents[2]))
+		gomega.Expect(err).To(gomega.MatchError(digiposte.ErrValidation))
+	})
+})
@@ -0,0 +1,113 @@
+package digiposte
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors returned by the API, to be checked with errors.Is.
+var (
+	// ErrUnauthorized means the session is missing or expired.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden means the user is not allowed to perform the request.
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound means the document, folder or share does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict means the request conflicts with the current state, such as a name already used.
+	ErrConflict = errors.New("conflict")
+	// ErrQuotaExceeded means the safe is full.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrRateLimited means the server throttled the request.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrServerUnavailable means the server failed or is temporarily unavailable.
+	ErrServerUnavailable = errors.New("server unavailable")
+	// ErrValidation means the server rejected the parameters of the request.
+	ErrValidation = errors.New("validation failed")
+)
+
+// APIError is returned when the API answers with an unexpected status.
+// It matches one of the Err* sentinels with errors.Is, when the failure is known,
+// and holds the decoded RequestErrors, reachable with errors.As.
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+
+	// Kind is one of the Err* sentinels, or nil if the failure is unknown.
+	Kind error
+
+	// Errors is the body of the response.
+	Errors *RequestErrors
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %q: HTTP %d %s: %v", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode), e.Errors)
+}
+
+func (e *APIError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Errors}
+	}
+
+	return []error{e.Kind, e.Errors}
+}
+
+// newAPIError returns the error of a response with an unexpected status.
+func newAPIError(req *http.Request, statusCode int, errs *RequestErrors) *APIError {
+	apiErr := &APIError{
+		Method:     "",
+		URL:        "",
+		StatusCode: statusCode,
+		Kind:       errorKind(statusCode, errs),
+		Errors:     errs,
+	}
+
+	if req != nil {
+		apiErr.Method = req.Method
+		apiErr.URL = req.URL.String()
+	}
+
+	return apiErr
+}
+
+// errorCodes maps the error codes answered by the API to the sentinels.
+// The other failures are mapped from their status.
+//
+//nolint:gochecknoglobals
+var errorCodes = map[string]error{
+	"unauthorized": ErrUnauthorized,
+}
+
+// errorKind returns the sentinel matching the error codes, or else the status.
+func errorKind(statusCode int, errs *RequestErrors) error {
+	if errs != nil {
+		for _, requestErr := range *errs {
+			if kind, ok := errorCodes[requestErr.ErrorCode]; ok {
+				return kind
+			}
+		}
+	}
+
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound, http.StatusGone:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusInsufficientStorage:
+		return ErrQuotaExceeded
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrServerUnavailable
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrValidation
+	default:
+		return nil
+	}
+}
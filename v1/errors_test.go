@@ -0,0 +1,87 @@
+package digiposte_test
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+var _ = ginkgo.Describe("APIError", func() {
+	var (
+		server *ghttp.Server
+		client *digiposte.Client
+	)
+
+	ginkgo.BeforeEach(func() {
+		server = ghttp.NewServer()
+		ginkgo.DeferCleanup(server.Close)
+
+		client = digiposte.NewCustomClient(server.URL(), server.URL(), nil)
+	})
+
+	ginkgo.DescribeTable("Should match the sentinel",
+		func(ctx ginkgo.SpecContext, status int, code string, expected error) {
+			server.AppendHandlers(ghttp.RespondWithJSONEncoded(status, digiposte.RequestErrors{{
+				ErrorCode: code,
+				ErrorDesc: "description",
+			}}))
+
+			_, err := client.ListFolders(ctx)
+			gomega.Expect(err).To(gomega.MatchError(expected))
+		},
+		ginkgo.Entry("401", http.StatusUnauthorized, "whatever", digiposte.ErrUnauthorized),
+		ginkgo.Entry("403", http.StatusForbidden, "whatever", digiposte.ErrForbidden),
+		ginkgo.Entry("404", http.StatusNotFound, "whatever", digiposte.ErrNotFound),
+		ginkgo.Entry("409", http.StatusConflict, "whatever", digiposte.ErrConflict),
+		ginkgo.Entry("429", http.StatusTooManyRequests, "whatever", digiposte.ErrRateLimited),
+		ginkgo.Entry("503", http.StatusServiceUnavailable, "whatever", digiposte.ErrServerUnavailable),
+		ginkgo.Entry("400", http.StatusBadRequest, "whatever", digiposte.ErrValidation),
+		ginkgo.Entry("an error code", http.StatusForbidden, "unauthorized", digiposte.ErrUnauthorized),
+		ginkgo.Entry("an unknown error code", http.StatusBadRequest, "document_not_found", digiposte.ErrValidation),
+	)
+
+	ginkgo.It("Should keep the request and the raw errors", func(ctx ginkgo.SpecContext) {
+		server.AppendHandlers(ghttp.RespondWithJSONEncoded(http.StatusNotFound, digiposte.RequestErrors{{
+			ErrorCode: "not_found",
+			ErrorDesc: "Folder not found.",
+		}}))
+
+		_, err := client.ListFolders(ctx)
+
+		var apiErr *digiposte.APIError
+		gomega.Expect(errors.As(err, &apiErr)).To(gomega.BeTrue())
+		gomega.Expect(apiErr.Method).To(gomega.Equal(http.MethodGet))
+		gomega.Expect(apiErr.URL).To(gomega.Equal(server.URL() + "/v3/folders"))
+		gomega.Expect(apiErr.StatusCode).To(gomega.Equal(http.StatusNotFound))
+
+		var requestErrs *digiposte.RequestErrors
+		gomega.Expect(errors.As(err, &requestErrs)).To(gomega.BeTrue())
+		gomega.Expect(*requestErrs).To(gomega.HaveLen(1))
+		gomega.Expect((*requestErrs)[0].ErrorDesc).To(gomega.Equal("Folder not found."))
+
+		gomega.Expect(err.Error()).To(gomega.ContainSubstring(`GET "` + server.URL() + `/v3/folders"`))
+	})
+
+	ginkgo.It("Should map the status when the body cannot be decoded", func(ctx ginkgo.SpecContext) {
+		server.AppendHandlers(ghttp.RespondWith(http.StatusBadGateway, "<html>Bad gateway</html>"))
+
+		_, err := client.ListFolders(ctx)
+		gomega.Expect(err).To(gomega.MatchError(digiposte.ErrServerUnavailable))
+		gomega.Expect(errors.Is(err, digiposte.ErrNotFound)).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("Should not match any sentinel for an unknown failure", func(ctx ginkgo.SpecContext) {
+		server.AppendHandlers(ghttp.RespondWith(http.StatusTeapot, nil))
+
+		_, err := client.ListFolders(ctx)
+
+		var apiErr *digiposte.APIError
+		gomega.Expect(errors.As(err, &apiErr)).To(gomega.BeTrue())
+		gomega.Expect(apiErr.Kind).To(gomega.BeNil())
+	})
+})
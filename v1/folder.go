@@ -22,6 +22,9 @@ type Folder struct {
 	UpdatedAt     time.Time `json:"updated_at"`
 	DocumentCount int64     `json:"document_count"`
 	Folders       []*Folder `json:"folders"`
+	// ParentID is the ID of the parent folder, or RootFolderID for the top-most folders
+	// and when the API does not report it.
+	ParentID FolderID `json:"parent_id,omitempty"`
 }
 
 // SearchFoldersResult represents a search result for folders.
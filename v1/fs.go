@@ -0,0 +1,510 @@
+package digiposte
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FS is a read-only file system over a folder of the safe.
+// Folders are directories and documents are files.
+// It implements fs.FS, fs.ReadDirFS and fs.StatFS.
+//
+// The folder tree and the content of each folder are fetched once, on first use:
+// create a new FS to see the changes made afterwards.
+//
+// Names are made valid and unique inside each folder:
+//   - "/" is replaced by "_", and the names "", "." and ".." are prefixed by "_",
+//   - when several documents or folders have the same name, the oldest one keeps it
+//     and the ID of the others is inserted before their extension, e.g. "avis~<id>.pdf".
+//
+// The Sys method of the fs.FileInfo returns the *Document or the *Folder.
+type FS struct {
+	ctx    context.Context //nolint:containedctx
+	client *Client
+	rootID FolderID
+
+	lock sync.Mutex
+	root *fsNode
+}
+
+var (
+	_ fs.FS        = (*FS)(nil)
+	_ fs.ReadDirFS = (*FS)(nil)
+	_ fs.StatFS    = (*FS)(nil)
+)
+
+// FS returns a file system rooted at the given folder. Use RootFolderID for the whole safe.
+// The context is used by all the requests sent by the file system.
+func (c *Client) FS(ctx context.Context, rootID FolderID) *FS {
+	return &FS{
+		ctx:    ctx,
+		client: c,
+		rootID: rootID,
+		lock:   sync.Mutex{},
+		root:   nil,
+	}
+}
+
+// fsNode is a folder or a document of the file system.
+type fsNode struct {
+	name     string
+	folder   *Folder
+	document *Document
+
+	// children are the content of a folder, sorted by name. They are loaded on first use.
+	children []*fsNode
+	loaded   bool
+}
+
+func (n *fsNode) id() string {
+	if n.document != nil {
+		return string(n.document.InternalID)
+	}
+
+	return string(n.folder.InternalID)
+}
+
+func (n *fsNode) createdAt() time.Time {
+	if n.document != nil {
+		return n.document.CreatedAt
+	}
+
+	return n.folder.CreatedAt
+}
+
+// Open opens the named file or directory.
+func (f *FS) Open(name string) (fs.File, error) {
+	node, err := f.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if node.document != nil {
+		return &fsFile{fsys: f, node: node, stream: nil, position: 0, offset: 0}, nil
+	}
+
+	children, err := f.children("open", name, node)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fsDir{node: node, children: children}, nil
+}
+
+// ReadDir reads the named directory and returns its entries sorted by name.
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	node, err := f.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if node.document != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errNotDirectory}
+	}
+
+	children, err := f.children("readdir", name, node)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]fs.DirEntry, 0, len(children))
+
+	for _, child := range children {
+		entries = append(entries, fs.FileInfoToDirEntry(&fsFileInfo{node: child}))
+	}
+
+	return entries, nil
+}
+
+// Stat returns the fs.FileInfo of the named file or directory.
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	node, err := f.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fsFileInfo{node: node}, nil
+}
+
+var (
+	errNotDirectory = errors.New("not a directory")
+	errRootNotFound = errors.New("root folder not found")
+)
+
+// lookup returns the node of the given path.
+func (f *FS) lookup(op, name string) (*fsNode, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	node, err := f.rootNode()
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	if name == "." {
+		return node, nil
+	}
+
+	for _, segment := range strings.Split(name, "/") {
+		if node.document != nil {
+			return nil, &fs.PathError{Op: op, Path: name, Err: errNotDirectory}
+		}
+
+		if err := f.load(node); err != nil {
+			return nil, &fs.PathError{Op: op, Path: name, Err: err}
+		}
+
+		index := sort.Search(len(node.children), func(i int) bool {
+			return node.children[i].name >= segment
+		})
+
+		if index == len(node.children) || node.children[index].name != segment {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+
+		node = node.children[index]
+	}
+
+	return node, nil
+}
+
+// children returns the content of a folder.
+func (f *FS) children(op, name string, node *fsNode) ([]*fsNode, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if err := f.load(node); err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	return node.children, nil
+}
+
+// rootNode returns the root of the file system. The caller must hold the lock.
+func (f *FS) rootNode() (*fsNode, error) {
+	if f.root != nil {
+		return f.root, nil
+	}
+
+	result, err := f.client.ListFolders(f.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list folders: %w", err)
+	}
+
+	root := &Folder{
+		InternalID:    RootFolderID,
+		Name:          ".",
+		CreatedAt:     time.Time{},
+		UpdatedAt:     time.Time{},
+		DocumentCount: 0,
+		Folders:       result.Folders,
+		ParentID:      RootFolderID,
+	}
+
+	if f.rootID != RootFolderID {
+		if root = findFolderByID(result.Folders, f.rootID); root == nil {
+			return nil, fmt.Errorf("%w: %s", errRootNotFound, f.rootID)
+		}
+	}
+
+	f.root = &fsNode{name: ".", folder: root, document: nil, children: nil, loaded: false}
+
+	return f.root, nil
+}
+
+func findFolderByID(folders []*Folder, id FolderID) *Folder {
+	for _, folder := range folders {
+		if folder.InternalID == id {
+			return folder
+		}
+
+		if found := findFolderByID(folder.Folders, id); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// load fetches the documents of a folder. The caller must hold the lock.
+func (f *FS) load(node *fsNode) error {
+	if node.loaded {
+		return nil
+	}
+
+	documents, err := f.client.SearchDocumentsIter(f.ctx, node.folder.InternalID).All()
+	if err != nil {
+		return fmt.Errorf("search documents: %w", err)
+	}
+
+	children := make([]*fsNode, 0, len(node.folder.Folders)+len(documents))
+
+	for _, folder := range node.folder.Folders {
+		children = append(children, &fsNode{name: folder.Name, folder: folder, document: nil, children: nil, loaded: false})
+	}
+
+	for _, document := range documents {
+		children = append(children, &fsNode{name: document.Name, folder: nil, document: document, children: nil, loaded: true})
+	}
+
+	disambiguate(children)
+
+	node.children = children
+	node.loaded = true
+
+	return nil
+}
+
+// disambiguate makes the names of the nodes valid and unique, then sorts the nodes by name.
+func disambiguate(nodes []*fsNode) {
+	groups := make(map[string][]*fsNode, len(nodes))
+
+	for _, node := range nodes {
+		node.name = SanitizeName(node.name)
+		groups[node.name] = append(groups[node.name], node)
+	}
+
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+
+		sort.Slice(group, func(i, j int) bool {
+			if !group[i].createdAt().Equal(group[j].createdAt()) {
+				return group[i].createdAt().Before(group[j].createdAt())
+			}
+
+			return group[i].id() < group[j].id()
+		})
+
+		for _, node := range group[1:] {
+			ext := path.Ext(node.name)
+			if ext == node.name || node.folder != nil {
+				ext = ""
+			}
+
+			node.name = strings.TrimSuffix(node.name, ext) + "~" + node.id() + ext
+		}
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].name < nodes[j].name
+	})
+}
+
+// SanitizeName returns the name of a document or a folder usable as a segment of a slash-separated path:
+// the slashes are replaced by underscores, and the names "", "." and ".." are prefixed with one.
+func SanitizeName(name string) string {
+	name = strings.ReplaceAll(name, "/", "_")
+
+	switch name {
+	case "", ".", "..":
+		return "_" + name
+	default:
+		return name
+	}
+}
+
+// fsFileInfo describes a document or a folder.
+type fsFileInfo struct {
+	node *fsNode
+}
+
+func (i *fsFileInfo) Name() string {
+	return i.node.name
+}
+
+func (i *fsFileInfo) Size() int64 {
+	if i.node.document != nil {
+		return i.node.document.Size
+	}
+
+	return 0
+}
+
+func (i *fsFileInfo) Mode() fs.FileMode {
+	if i.node.document != nil {
+		return 0o444
+	}
+
+	return fs.ModeDir | 0o555
+}
+
+func (i *fsFileInfo) ModTime() time.Time {
+	return i.node.createdAt()
+}
+
+func (i *fsFileInfo) IsDir() bool {
+	return i.node.document == nil
+}
+
+// Sys returns the *Document or the *Folder.
+func (i *fsFileInfo) Sys() interface{} {
+	if i.node.document != nil {
+		return i.node.document
+	}
+
+	return i.node.folder
+}
+
+// fsDir is an open folder.
+type fsDir struct {
+	node     *fsNode
+	children []*fsNode
+}
+
+func (d *fsDir) Stat() (fs.FileInfo, error) {
+	return &fsFileInfo{node: d.node}, nil
+}
+
+func (d *fsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.node.name, Err: errIsDirectory}
+}
+
+func (d *fsDir) Close() error {
+	return nil
+}
+
+// ReadDir reads the next n entries of the folder, like fs.ReadDirFile.
+func (d *fsDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	if count > 0 && len(d.children) == 0 {
+		return nil, io.EOF
+	}
+
+	if count <= 0 || count > len(d.children) {
+		count = len(d.children)
+	}
+
+	entries := make([]fs.DirEntry, 0, count)
+
+	for _, child := range d.children[:count] {
+		entries = append(entries, fs.FileInfoToDirEntry(&fsFileInfo{node: child}))
+	}
+
+	d.children = d.children[count:]
+
+	return entries, nil
+}
+
+var (
+	errIsDirectory   = errors.New("is a directory")
+	errInvalidWhence = errors.New("invalid whence")
+	errNegativeSeek  = errors.New("negative position")
+)
+
+// fsFile is an open document. The content is downloaded on the first read,
+// and downloaded again when seeking backward.
+// The end of the file is the end of the content: the reported size is only used by Stat and io.SeekEnd.
+type fsFile struct {
+	fsys *FS
+	node *fsNode
+
+	stream *DocumentStreamReader
+	// position is the offset of the stream, offset is the offset of the file.
+	position int64
+	offset   int64
+}
+
+func (f *fsFile) Stat() (fs.FileInfo, error) {
+	return &fsFileInfo{node: f.node}, nil
+}
+
+func (f *fsFile) Read(buf []byte) (int, error) {
+	if err := f.seekStream(); err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, io.EOF
+		}
+
+		return 0, &fs.PathError{Op: "read", Path: f.node.name, Err: err}
+	}
+
+	n, err := f.stream.Read(buf)
+	f.position += int64(n)
+	f.offset += int64(n)
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		return n, &fs.PathError{Op: "read", Path: f.node.name, Err: err}
+	}
+
+	return n, err //nolint:wrapcheck
+}
+
+// seekStream moves the stream to the offset of the file.
+func (f *fsFile) seekStream() error {
+	if f.stream != nil && f.position > f.offset {
+		if err := f.stream.Close(); err != nil {
+			return fmt.Errorf("close stream: %w", err)
+		}
+
+		f.stream = nil
+	}
+
+	if f.stream == nil {
+		stream, err := f.fsys.client.DocumentStream(f.fsys.ctx, f.node.document.InternalID)
+		if err != nil {
+			return fmt.Errorf("get document content: %w", err)
+		}
+
+		f.stream = stream
+		f.position = 0
+	}
+
+	if f.position < f.offset {
+		skipped, err := io.CopyN(io.Discard, f.stream, f.offset-f.position)
+		f.position += skipped
+
+		if err != nil {
+			return fmt.Errorf("skip content: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Seek sets the offset for the next Read. The content is downloaded again when seeking backward.
+func (f *fsFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.node.document.Size
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: f.node.name, Err: errInvalidWhence}
+	}
+
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.node.name, Err: errNegativeSeek}
+	}
+
+	f.offset = offset
+
+	return offset, nil
+}
+
+func (f *fsFile) Close() error {
+	if f.stream == nil {
+		return nil
+	}
+
+	err := f.stream.Close()
+	f.stream = nil
+
+	if err != nil {
+		return &fs.PathError{Op: "close", Path: f.node.name, Err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package digiposte_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing/fstest"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/onsi/gomega/gstruct"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+var _ = ginkgo.Describe("FS", func() {
+	var (
+		folder    *digiposte.Folder
+		subFolder *digiposte.Folder
+		documents []digiposte.DocumentID
+	)
+
+	createDocument := func(ctx ginkgo.SpecContext, folderID digiposte.FolderID, name, content string) {
+		document, err := digiposteClient.CreateDocument(ctx,
+			folderID,
+			name,
+			strings.NewReader(content),
+			digiposte.DocumentTypeBasic,
+		)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		documents = append(documents, document.InternalID)
+	}
+
+	ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+		var err error
+
+		folder, err = digiposteClient.CreateFolder(ctx, digiposte.RootFolderID, ginkgo.CurrentSpecReport().FullText())
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		subFolder, err = digiposteClient.CreateFolder(ctx, folder.InternalID, "sub")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		documents = nil
+
+		createDocument(ctx, folder.InternalID, "avis.txt", "the content")
+		createDocument(ctx, folder.InternalID, "avis.txt", "another content")
+		createDocument(ctx, subFolder.InternalID, "a/b.txt", "nested content")
+	})
+
+	ginkgo.AfterEach(func(ctx ginkgo.SpecContext) {
+		folders := []digiposte.FolderID{folder.InternalID}
+
+		gomega.Expect(digiposteClient.Trash(ctx, documents, folders)).To(gomega.Succeed())
+		gomega.Expect(digiposteClient.Delete(ctx, documents, folders)).To(gomega.Succeed())
+	})
+
+	// renamedDocument returns the name given to the document that did not keep the name "avis.txt".
+	renamedDocument := func(fsys fs.FS) string {
+		info, err := fs.Stat(fsys, "avis.txt")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		kept, ok := info.Sys().(*digiposte.Document)
+		gomega.Expect(ok).To(gomega.BeTrue())
+
+		for _, id := range documents[:2] {
+			if id != kept.InternalID {
+				return "avis~" + string(id) + ".txt"
+			}
+		}
+
+		ginkgo.Fail("both documents kept their name")
+
+		return ""
+	}
+
+	ginkgo.It("Should pass fstest.TestFS", func(ctx ginkgo.SpecContext) {
+		fsys := digiposteClient.FS(ctx, folder.InternalID)
+
+		gomega.Expect(fstest.TestFS(fsys, "avis.txt", renamedDocument(fsys), "sub/a_b.txt")).To(gomega.Succeed())
+	})
+
+	ginkgo.It("Should map the documents to files", func(ctx ginkgo.SpecContext) {
+		fsys := digiposteClient.FS(ctx, folder.InternalID)
+
+		content, err := fs.ReadFile(fsys, "sub/a_b.txt")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(string(content)).To(gomega.Equal("nested content"))
+
+		info, err := fs.Stat(fsys, "sub/a_b.txt")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(info.Size()).To(gomega.BeEquivalentTo(len("nested content")))
+		gomega.Expect(info.Sys()).To(gstruct.PointTo(gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+			"InternalID": gomega.Equal(documents[2]),
+			"MimeType":   gomega.Equal("text/plain"),
+		})))
+
+		_, err = fs.Stat(fsys, "missing.txt")
+		gomega.Expect(err).To(gomega.MatchError(fs.ErrNotExist))
+	})
+
+	ginkgo.It("Should walk the folders", func(ctx ginkgo.SpecContext) {
+		var paths []string
+
+		fsys := digiposteClient.FS(ctx, folder.InternalID)
+
+		gomega.Expect(fs.WalkDir(fsys, ".",
+			func(path string, _ fs.DirEntry, err error) error {
+				paths = append(paths, path)
+
+				return err
+			},
+		)).To(gomega.Succeed())
+
+		gomega.Expect(paths).To(gomega.Equal([]string{
+			".", "avis.txt", renamedDocument(fsys), "sub", "sub/a_b.txt",
+		}))
+	})
+
+	ginkgo.It("Should serve the documents over HTTP", func(ctx ginkgo.SpecContext) {
+		recorder := httptest.NewRecorder()
+
+		http.FileServer(http.FS(digiposteClient.FS(ctx, folder.InternalID))).
+			ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/avis.txt", nil))
+
+		gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+		gomega.Expect(recorder.Body.String()).To(gomega.Or(gomega.Equal("the content"), gomega.Equal("another content")))
+	})
+
+	ginkgo.It("Should read the content beyond the reported size", func(ctx ginkgo.SpecContext) {
+		server := ghttp.NewServer()
+		ginkgo.DeferCleanup(server.Close)
+
+		server.RouteToHandler(http.MethodGet, "/v3/folders",
+			ghttp.RespondWithJSONEncoded(http.StatusOK, &digiposte.SearchFoldersResult{}))
+		server.RouteToHandler(http.MethodPost, "/v3/documents/search",
+			ghttp.RespondWithJSONEncoded(http.StatusOK, &digiposte.SearchDocumentsResult{
+				Count:     1,
+				Documents: []*digiposte.Document{{InternalID: "document", Name: "avis.txt", Size: 3}},
+			}))
+		server.RouteToHandler(http.MethodGet, "/rest/content/document/document",
+			ghttp.RespondWith(http.StatusOK, "the whole content"))
+
+		fsys := digiposte.NewCustomClient(server.URL(), server.URL(), nil).FS(ctx, digiposte.RootFolderID)
+
+		content, err := fs.ReadFile(fsys, "avis.txt")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(string(content)).To(gomega.Equal("the whole content"))
+	})
+})
@@ -0,0 +1,154 @@
+package digiposte
+
+import (
+	"context"
+	"fmt"
+)
+
+// DocumentIterator iterates over the results of a search, fetching the pages lazily.
+//
+//	iter := client.SearchDocumentsIter(ctx, digiposte.RootFolderID, digiposte.WithPageSize(100))
+//	for iter.Next() {
+//		doc := iter.Document()
+//		// ...
+//	}
+//	if err := iter.Err(); err != nil {
+//		// ...
+//	}
+type DocumentIterator struct {
+	ctx   context.Context //nolint:containedctx
+	fetch func(ctx context.Context, index int64) (*SearchDocumentsResult, error)
+
+	page    []*Document
+	current *Document
+	index   int64
+	count   int64
+	err     error
+	done    bool
+}
+
+// SearchDocumentsIter returns an iterator over all the documents matching the search.
+// It accepts the same options as SearchDocuments.
+func (c *Client) SearchDocumentsIter(
+	ctx context.Context,
+	internalID FolderID,
+	options ...DocumentSearchOption,
+) *DocumentIterator {
+	return newDocumentIterator(ctx, func(ctx context.Context, index int64) (*SearchDocumentsResult, error) {
+		return c.SearchDocuments(ctx, internalID, append(options[:len(options):len(options)], FromIndex(index))...)
+	})
+}
+
+// TrashedDocumentsIter returns an iterator over all the documents in the trash.
+// It accepts the same options as GetTrashedDocuments.
+func (c *Client) TrashedDocumentsIter(ctx context.Context, options ...DocumentSearchOption) *DocumentIterator {
+	return newDocumentIterator(ctx, func(ctx context.Context, index int64) (*SearchDocumentsResult, error) {
+		return c.GetTrashedDocuments(ctx, append(options[:len(options):len(options)], FromIndex(index))...)
+	})
+}
+
+func newDocumentIterator(
+	ctx context.Context,
+	fetch func(ctx context.Context, index int64) (*SearchDocumentsResult, error),
+) *DocumentIterator {
+	return &DocumentIterator{
+		ctx:     ctx,
+		fetch:   fetch,
+		page:    nil,
+		current: nil,
+		index:   0,
+		count:   -1,
+		err:     nil,
+		done:    false,
+	}
+}
+
+// Next advances the iterator to the next document, fetching the next page if needed.
+// It returns false when there are no more documents or when an error occurred.
+func (it *DocumentIterator) Next() bool {
+	it.current = nil
+
+	if it.done || it.err != nil {
+		return false
+	}
+
+	for len(it.page) == 0 {
+		if it.count >= 0 && it.index >= it.count {
+			it.done = true
+
+			return false
+		}
+
+		if err := it.ctx.Err(); err != nil {
+			it.err = fmt.Errorf("context done: %w", err)
+
+			return false
+		}
+
+		result, err := it.fetch(it.ctx, it.index)
+		if err != nil {
+			it.err = &PageError{Index: it.index, Err: err}
+
+			return false
+		}
+
+		// Stop on an empty page, otherwise the iterator would loop forever.
+		if len(result.Documents) == 0 {
+			it.done = true
+
+			return false
+		}
+
+		it.count = result.Count
+		it.index += int64(len(result.Documents))
+		it.page = result.Documents
+	}
+
+	it.current, it.page = it.page[0], it.page[1:]
+
+	return true
+}
+
+// Document returns the current document.
+// It must only be called after a call to Next returning true.
+func (it *DocumentIterator) Document() *Document {
+	return it.current
+}
+
+// Count returns the total number of documents matching the search, as reported by the last page.
+// It returns -1 until the first page is fetched.
+func (it *DocumentIterator) Count() int64 {
+	return it.count
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *DocumentIterator) Err() error {
+	return it.err
+}
+
+// All consumes the iterator and returns all the remaining documents.
+// On error, the documents fetched so far are returned along with the error.
+func (it *DocumentIterator) All() ([]*Document, error) {
+	var documents []*Document
+
+	for it.Next() {
+		documents = append(documents, it.Document())
+	}
+
+	return documents, it.Err()
+}
+
+// PageError is an error returned when a page of search results cannot be fetched.
+// The documents of the previous pages have already been returned by the iterator.
+type PageError struct {
+	Index int64
+	Err   error
+}
+
+func (e *PageError) Error() string {
+	return fmt.Sprintf("page at index %d: %v", e.Index, e.Err)
+}
+
+func (e *PageError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,115 @@
+package digiposte_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/onsi/gomega/gstruct"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+var _ = ginkgo.Describe("DocumentIterator", func() {
+	ginkgo.Context("Authenticated", func() {
+		var (
+			folder    *digiposte.Folder
+			documents []digiposte.DocumentID
+		)
+
+		ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+			var err error
+
+			folder, err = digiposteClient.CreateFolder(ctx, digiposte.RootFolderID, ginkgo.CurrentSpecReport().FullText())
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			documents = nil
+
+			for i := 0; i < 5; i++ {
+				document, err := digiposteClient.CreateDocument(ctx,
+					folder.InternalID,
+					fmt.Sprintf("document %d.txt", i),
+					strings.NewReader("the content"),
+					digiposte.DocumentTypeBasic,
+				)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+				documents = append(documents, document.InternalID)
+			}
+		})
+
+		ginkgo.AfterEach(func(ctx ginkgo.SpecContext) {
+			folders := []digiposte.FolderID{folder.InternalID}
+
+			gomega.Expect(digiposteClient.Trash(ctx, documents, folders)).To(gomega.Succeed())
+			gomega.Expect(digiposteClient.Delete(ctx, documents, folders)).To(gomega.Succeed())
+		})
+
+		ginkgo.It("Should follow the pages", func(ctx ginkgo.SpecContext) {
+			iter := digiposteClient.SearchDocumentsIter(ctx, folder.InternalID, digiposte.WithPageSize(2))
+
+			var found []digiposte.DocumentID
+
+			for iter.Next() {
+				found = append(found, iter.Document().InternalID)
+			}
+
+			gomega.Expect(iter.Err()).ToNot(gomega.HaveOccurred())
+			gomega.Expect(iter.Count()).To(gomega.BeEquivalentTo(len(documents)))
+			gomega.Expect(found).To(gomega.ConsistOf(documents))
+		})
+
+		ginkgo.It("Should stop when the context is canceled", func(ctx ginkgo.SpecContext) {
+			canceledCtx, cancel := context.WithCancel(ctx)
+			cancel()
+
+			iter := digiposteClient.SearchDocumentsIter(canceledCtx, folder.InternalID, digiposte.WithPageSize(2))
+
+			gomega.Expect(iter.Next()).To(gomega.BeFalse())
+			gomega.Expect(iter.Err()).To(gomega.MatchError(context.Canceled))
+		})
+	})
+
+	ginkgo.Context("When a page fails", func() {
+		var server *ghttp.Server
+
+		ginkgo.BeforeEach(func() {
+			server = ghttp.NewServer()
+			ginkgo.DeferCleanup(server.Close)
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodPost, "/v3/documents/search", "index=0&max_results=2&sort=TITLE"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, &digiposte.SearchDocumentsResult{
+						Count:      3,
+						Index:      0,
+						MaxResults: 2,
+						Documents:  []*digiposte.Document{{InternalID: "first"}, {InternalID: "second"}},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(http.MethodPost, "/v3/documents/search", "index=2&max_results=2&sort=TITLE"),
+					ghttp.RespondWithJSONEncoded(http.StatusServiceUnavailable, digiposte.RequestErrors{{
+						ErrorCode: "unavailable",
+						ErrorDesc: "try again later",
+					}}),
+				),
+			)
+		})
+
+		ginkgo.It("Should return the documents of the previous pages and the error", func(ctx ginkgo.SpecContext) {
+			client := digiposte.NewCustomClient(server.URL(), server.URL(), nil)
+
+			documents, err := client.SearchDocumentsIter(ctx, digiposte.RootFolderID, digiposte.WithPageSize(2)).All()
+			gomega.Expect(documents).To(gomega.HaveLen(2))
+			gomega.Expect(err).To(gomega.BeAssignableToTypeOf(&digiposte.PageError{}))
+			gomega.Expect(err).To(gstruct.PointTo(gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+				"Index": gomega.BeEquivalentTo(2),
+			})))
+		})
+	})
+})
@@ -0,0 +1,24 @@
+package digiposte
+
+import (
+	"log/slog"
+
+	"github.com/holyhope/digiposte-go-sdk/internal/utils"
+)
+
+// WithLogger logs the requests at the debug level, and the retries and throttling at the warn level.
+// The records have the method and url attributes. By default, nothing is logged.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// log returns the logger of the client, never nil.
+func (c *clientHelper) log() *slog.Logger {
+	if c.logger == nil {
+		return utils.DiscardLogger()
+	}
+
+	return c.logger
+}
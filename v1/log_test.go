@@ -0,0 +1,70 @@
+package digiposte_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+var _ = ginkgo.Describe("WithLogger", func() {
+	var (
+		server *ghttp.Server
+		output *bytes.Buffer
+		client *digiposte.Client
+	)
+
+	ginkgo.BeforeEach(func() {
+		server = ghttp.NewServer()
+		ginkgo.DeferCleanup(server.Close)
+
+		output = new(bytes.Buffer)
+
+		client = digiposte.NewCustomClient(server.URL(), server.URL(), nil,
+			digiposte.WithLogger(slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{
+				AddSource:   false,
+				Level:       slog.LevelDebug,
+				ReplaceAttr: nil,
+			}))),
+			digiposte.WithRetryPolicy(&digiposte.RetryPolicy{
+				MaxAttempts:       2,
+				InitialBackoff:    time.Millisecond,
+				MaxBackoff:        time.Millisecond,
+				Multiplier:        1,
+				Jitter:            0,
+				RetryableStatuses: nil,
+			}),
+		)
+	})
+
+	ginkgo.It("Should log the requests and the retries", func(ctx ginkgo.SpecContext) {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusTooManyRequests, nil),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, &digiposte.SearchFoldersResult{}),
+		)
+
+		_, err := client.ListFolders(ctx)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		gomega.Expect(output.String()).To(gomega.And(
+			gomega.ContainSubstring(`level=DEBUG msg="Request sent" method=GET url=`+server.URL()+`/v3/folders status=429`),
+			gomega.ContainSubstring(`level=WARN msg="Throttled by the server"`),
+			gomega.ContainSubstring(`level=WARN msg="Retrying request" method=GET url=`+server.URL()+`/v3/folders attempt=1`),
+			gomega.ContainSubstring(`status=200`),
+		))
+	})
+
+	ginkgo.It("Should not require a logger", func(ctx ginkgo.SpecContext) {
+		server.AppendHandlers(ghttp.RespondWithJSONEncoded(http.StatusOK, &digiposte.SearchFoldersResult{}))
+
+		_, err := digiposte.NewCustomClient(server.URL(), server.URL(), nil).ListFolders(ctx)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(output.String()).To(gomega.BeEmpty())
+	})
+})
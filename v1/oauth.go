@@ -19,7 +19,7 @@ type TokenSource struct {
 
 func NewTokenSource(c *http.Client, documentURL string, getContext func() context.Context) *TokenSource {
 	return &TokenSource{
-		clientHelper: &clientHelper{client: c},
+		clientHelper: &clientHelper{client: c, retryPolicy: nil, rateLimiters: nil, logger: nil},
 		DocumentURL:  documentURL,
 		GetContext:   getContext,
 	}
@@ -0,0 +1,431 @@
+package digiposte
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// Errors of the path resolution, wrapped in a *PathError.
+// A missing document or folder is reported with ErrNotFound.
+var (
+	// ErrAmbiguousPath means several documents or folders have the same name.
+	ErrAmbiguousPath = errors.New("ambiguous name")
+	// ErrNotFolder means a folder was expected.
+	ErrNotFolder = errors.New("not a folder")
+	// ErrNotDocument means a document was expected.
+	ErrNotDocument = errors.New("not a document")
+)
+
+// PathError is returned when a path cannot be resolved.
+type PathError struct {
+	// Path is the path being resolved.
+	Path string
+	// Segment is the name that failed, if any.
+	Segment string
+	Err     error
+}
+
+func (e *PathError) Error() string {
+	if e.Segment == "" {
+		return fmt.Sprintf("%s: %v", e.Path, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %q: %v", e.Path, e.Segment, e.Err)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// PathEntry is the document or the folder found at a path.
+type PathEntry struct {
+	// Path is the cleaned path, such as "Impôts/2024/avis.pdf", or "." for the root folder.
+	Path string
+
+	// Folder is set when the path is a folder. The root folder has the RootFolderID.
+	Folder *Folder
+	// Document is set when the path is a document.
+	Document *Document
+}
+
+// IsFolder reports whether the entry is a folder.
+func (e *PathEntry) IsFolder() bool {
+	return e.Folder != nil
+}
+
+// Resolver turns slash-separated paths, such as "Impôts/2024/avis.pdf", into documents and folders, and back.
+// A leading slash is ignored, "." and ".." are interpreted lexically.
+// The names are escaped with SanitizeName, like in FS, so a name holding a slash is a single segment.
+//
+// The folder tree is loaded on first use and kept: create a new resolver to see the later changes.
+// A Resolver is not safe for concurrent use.
+type Resolver struct {
+	client *Client
+	root   *Folder
+}
+
+// NewResolver returns a resolver over the safe of the user.
+func (c *Client) NewResolver() *Resolver {
+	return &Resolver{
+		client: c,
+		root:   nil,
+	}
+}
+
+// cleanPath returns the segments of the path.
+func cleanPath(name string) (string, []string) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+name), "/")
+	if cleaned == "" {
+		return ".", nil
+	}
+
+	return cleaned, strings.Split(cleaned, "/")
+}
+
+// rootFolder returns the folder tree, loading it on first use.
+func (r *Resolver) rootFolder(ctx context.Context) (*Folder, error) {
+	if r.root != nil {
+		return r.root, nil
+	}
+
+	root, err := r.client.folderTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.root = root
+
+	return r.root, nil
+}
+
+// folderTree returns a folder with the RootFolderID holding the folders at the root.
+func (c *Client) folderTree(ctx context.Context) (*Folder, error) {
+	result, err := c.ListFolders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list folders: %w", err)
+	}
+
+	return &Folder{
+		InternalID:    RootFolderID,
+		Name:          "",
+		CreatedAt:     time.Time{},
+		UpdatedAt:     time.Time{},
+		DocumentCount: 0,
+		Folders:       result.Folders,
+		ParentID:      RootFolderID,
+	}, nil
+}
+
+// Resolve returns the document or the folder at the given path.
+func (r *Resolver) Resolve(ctx context.Context, name string) (*PathEntry, error) {
+	cleaned, segments := cleanPath(name)
+
+	folder, err := r.rootFolder(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(segments) == 0 {
+		return &PathEntry{Path: cleaned, Folder: folder, Document: nil}, nil
+	}
+
+	for _, segment := range segments[:len(segments)-1] {
+		folder, err = childFolder(folder, segment)
+		if err != nil {
+			return nil, &PathError{Path: cleaned, Segment: segment, Err: err}
+		}
+	}
+
+	base := segments[len(segments)-1]
+
+	var candidates []*PathEntry
+
+	for _, sub := range folder.Folders {
+		if SanitizeName(sub.Name) == base {
+			candidates = append(candidates, &PathEntry{Path: cleaned, Folder: sub, Document: nil})
+		}
+	}
+
+	documents, err := r.client.SearchDocumentsIter(ctx, folder.InternalID).All()
+	if err != nil {
+		return nil, fmt.Errorf("search documents: %w", err)
+	}
+
+	for _, document := range documents {
+		if SanitizeName(document.Name) == base {
+			candidates = append(candidates, &PathEntry{Path: cleaned, Folder: nil, Document: document})
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, &PathError{Path: cleaned, Segment: base, Err: ErrNotFound}
+	case 1:
+		return candidates[0], nil
+	default:
+		return nil, &PathError{
+			Path:    cleaned,
+			Segment: base,
+			Err:     fmt.Errorf("%w: %d documents or folders have this name", ErrAmbiguousPath, len(candidates)),
+		}
+	}
+}
+
+// ResolveFolder returns the folder at the given path.
+func (r *Resolver) ResolveFolder(ctx context.Context, name string) (*Folder, error) {
+	entry, err := r.Resolve(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Folder == nil {
+		return nil, &PathError{Path: entry.Path, Segment: "", Err: ErrNotFolder}
+	}
+
+	return entry.Folder, nil
+}
+
+// ResolveDocument returns the document at the given path.
+func (r *Resolver) ResolveDocument(ctx context.Context, name string) (*Document, error) {
+	entry, err := r.Resolve(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Document == nil {
+		return nil, &PathError{Path: entry.Path, Segment: "", Err: ErrNotDocument}
+	}
+
+	return entry.Document, nil
+}
+
+// FolderPath returns the path of the folder, or "." for the root folder.
+func (r *Resolver) FolderPath(ctx context.Context, folderID FolderID) (string, error) {
+	root, err := r.rootFolder(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if folderID == RootFolderID {
+		return ".", nil
+	}
+
+	segments, ok := folderSegments(root, folderID)
+	if !ok {
+		return "", &PathError{Path: string(folderID), Segment: "", Err: ErrNotFound}
+	}
+
+	return strings.Join(segments, "/"), nil
+}
+
+// DocumentPath returns the path of the document, from its folder and its name.
+func (r *Resolver) DocumentPath(ctx context.Context, document *Document) (string, error) {
+	dir, err := r.FolderPath(ctx, FolderID(document.FolderID))
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(dir, SanitizeName(document.Name)), nil
+}
+
+// folderSegments returns the names of the folders leading to the given folder.
+func folderSegments(parent *Folder, folderID FolderID) ([]string, bool) {
+	for _, folder := range parent.Folders {
+		if folder.InternalID == folderID {
+			return []string{SanitizeName(folder.Name)}, true
+		}
+
+		if segments, ok := folderSegments(folder, folderID); ok {
+			return append([]string{SanitizeName(folder.Name)}, segments...), true
+		}
+	}
+
+	return nil, false
+}
+
+func childFolder(parent *Folder, name string) (*Folder, error) {
+	var found *Folder
+
+	for _, folder := range parent.Folders {
+		if SanitizeName(folder.Name) != name {
+			continue
+		}
+
+		if found != nil {
+			return nil, fmt.Errorf("%w: several folders have this name", ErrAmbiguousPath)
+		}
+
+		found = folder
+	}
+
+	if found == nil {
+		return nil, ErrNotFound
+	}
+
+	return found, nil
+}
+
+// StatPath returns the document or the folder at the given path.
+func (c *Client) StatPath(ctx context.Context, name string) (*PathEntry, error) {
+	return c.NewResolver().Resolve(ctx, name)
+}
+
+// OpenPath returns the content of the document at the given path. The caller must close it.
+func (c *Client) OpenPath(ctx context.Context, name string) (*DocumentStreamReader, error) {
+	document, err := c.NewResolver().ResolveDocument(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.DocumentStream(ctx, document.InternalID)
+}
+
+// UploadPath creates a document at the given path. The parent folder must exist.
+// It fails with ErrConflict if a document or a folder already has this path.
+func (c *Client) UploadPath(ctx context.Context, name string, data io.Reader, docType DocumentType) (*Document, error) {
+	resolver := c.NewResolver()
+
+	cleaned, segments := cleanPath(name)
+	if len(segments) == 0 {
+		return nil, &PathError{Path: cleaned, Segment: "", Err: ErrConflict}
+	}
+
+	parent, err := resolver.ResolveFolder(ctx, path.Dir(cleaned))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := resolver.Resolve(ctx, cleaned); err == nil {
+		return nil, &PathError{Path: cleaned, Segment: "", Err: ErrConflict}
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	return c.CreateDocument(ctx, parent.InternalID, path.Base(cleaned), data, docType)
+}
+
+// MovePath moves the document or the folder at the path from.
+// If to is an existing folder, the item is moved into it. Otherwise, the parent of to must be a folder,
+// and the item is moved and renamed there.
+func (c *Client) MovePath(ctx context.Context, from, to string) error {
+	resolver := c.NewResolver()
+
+	source, err := resolver.Resolve(ctx, from)
+	if err != nil {
+		return err
+	}
+
+	if source.Path == "." {
+		return &PathError{Path: source.Path, Segment: "", Err: fmt.Errorf("%w: cannot move the root folder", ErrValidation)}
+	}
+
+	destination, newName, err := moveDestination(ctx, resolver, to)
+	if err != nil {
+		return err
+	}
+
+	if source.Folder != nil {
+		return c.movePathFolder(ctx, source.Folder, destination, newName)
+	}
+
+	return c.movePathDocument(ctx, source.Document, destination, newName)
+}
+
+// moveDestination returns the folder to move into, and the new name if the item is renamed.
+func moveDestination(ctx context.Context, resolver *Resolver, to string) (*Folder, string, error) {
+	entry, err := resolver.Resolve(ctx, to)
+
+	switch {
+	case err == nil && entry.Folder != nil:
+		return entry.Folder, "", nil
+	case err == nil:
+		return nil, "", &PathError{Path: entry.Path, Segment: "", Err: ErrConflict}
+	case !errors.Is(err, ErrNotFound):
+		return nil, "", err
+	}
+
+	cleaned, _ := cleanPath(to)
+
+	parent, err := resolver.ResolveFolder(ctx, path.Dir(cleaned))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return parent, path.Base(cleaned), nil
+}
+
+func (c *Client) movePathFolder(ctx context.Context, folder, destination *Folder, newName string) error {
+	if !destinationHasFolder(destination, folder.InternalID) {
+		if err := c.Move(ctx, destination.InternalID, nil, []FolderID{folder.InternalID}); err != nil {
+			return fmt.Errorf("move: %w", err)
+		}
+	}
+
+	if newName != "" && newName != folder.Name {
+		if _, err := c.RenameFolder(ctx, folder.InternalID, newName); err != nil {
+			return fmt.Errorf("rename: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) movePathDocument(ctx context.Context, document *Document, destination *Folder, newName string) error {
+	if FolderID(document.FolderID) != destination.InternalID {
+		if err := c.Move(ctx, destination.InternalID, []DocumentID{document.InternalID}, nil); err != nil {
+			return fmt.Errorf("move: %w", err)
+		}
+	}
+
+	if newName != "" && newName != document.Name {
+		if _, err := c.RenameDocument(ctx, document.InternalID, newName); err != nil {
+			return fmt.Errorf("rename: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func destinationHasFolder(destination *Folder, folderID FolderID) bool {
+	for _, folder := range destination.Folders {
+		if folder.InternalID == folderID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TrashPath moves the documents and the folders at the given paths to the trash.
+// Nothing is trashed if a path cannot be resolved.
+func (c *Client) TrashPath(ctx context.Context, names ...string) error {
+	resolver := c.NewResolver()
+
+	var (
+		documentIDs []DocumentID
+		folderIDs   []FolderID
+	)
+
+	for _, name := range names {
+		entry, err := resolver.Resolve(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case entry.Document != nil:
+			documentIDs = append(documentIDs, entry.Document.InternalID)
+		case entry.Path == ".":
+			return &PathError{Path: entry.Path, Segment: "", Err: fmt.Errorf("%w: cannot trash the root folder", ErrValidation)}
+		default:
+			folderIDs = append(folderIDs, entry.Folder.InternalID)
+		}
+	}
+
+	return c.Trash(ctx, documentIDs, folderIDs)
+}
@@ -0,0 +1,166 @@
+package digiposte_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+var _ = ginkgo.Describe("Path", func() {
+	var (
+		folder    *digiposte.Folder
+		subFolder *digiposte.Folder
+		base      string
+		documents []digiposte.DocumentID
+	)
+
+	createDocument := func(ctx ginkgo.SpecContext, folderID digiposte.FolderID, name, content string) {
+		document, err := digiposteClient.CreateDocument(ctx,
+			folderID,
+			name,
+			strings.NewReader(content),
+			digiposte.DocumentTypeBasic,
+		)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		documents = append(documents, document.InternalID)
+	}
+
+	ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+		var err error
+
+		base = "path test " + ginkgo.CurrentSpecReport().LeafNodeText
+
+		folder, err = digiposteClient.CreateFolder(ctx, digiposte.RootFolderID, base)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		subFolder, err = digiposteClient.CreateFolder(ctx, folder.InternalID, "sub")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		documents = nil
+
+		createDocument(ctx, folder.InternalID, "avis.txt", "the content")
+		createDocument(ctx, subFolder.InternalID, "same.txt", "first")
+		createDocument(ctx, subFolder.InternalID, "same.txt", "second")
+	})
+
+	ginkgo.AfterEach(func(ctx ginkgo.SpecContext) {
+		folders := []digiposte.FolderID{folder.InternalID}
+
+		gomega.Expect(digiposteClient.Trash(ctx, documents, folders)).To(gomega.Succeed())
+		gomega.Expect(digiposteClient.Delete(ctx, documents, folders)).To(gomega.Succeed())
+	})
+
+	ginkgo.It("Should resolve paths and back", func(ctx ginkgo.SpecContext) {
+		resolver := digiposteClient.NewResolver()
+
+		entry, err := resolver.Resolve(ctx, "/"+base+"/./sub/../avis.txt")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(entry.Path).To(gomega.Equal(base + "/avis.txt"))
+		gomega.Expect(entry.IsFolder()).To(gomega.BeFalse())
+
+		documentPath, err := resolver.DocumentPath(ctx, entry.Document)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(documentPath).To(gomega.Equal(base + "/avis.txt"))
+
+		sub, err := resolver.ResolveFolder(ctx, base+"/sub")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(sub.InternalID).To(gomega.Equal(subFolder.InternalID))
+
+		folderPath, err := resolver.FolderPath(ctx, subFolder.InternalID)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(folderPath).To(gomega.Equal(base + "/sub"))
+
+		root, err := resolver.Resolve(ctx, "/")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(root.Path).To(gomega.Equal("."))
+		gomega.Expect(root.Folder.InternalID).To(gomega.Equal(digiposte.RootFolderID))
+	})
+
+	ginkgo.It("Should escape the slashes in the names", func(ctx ginkgo.SpecContext) {
+		escaped, err := digiposteClient.CreateFolder(ctx, folder.InternalID, "2024/2025")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		document, err := digiposteClient.CreateDocument(ctx,
+			escaped.InternalID,
+			"avis/impôts.txt",
+			strings.NewReader("escaped"),
+			digiposte.DocumentTypeBasic,
+		)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		documents = append(documents, document.InternalID)
+
+		resolver := digiposteClient.NewResolver()
+
+		documentPath, err := resolver.DocumentPath(ctx, document)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(documentPath).To(gomega.Equal(base + "/2024_2025/avis_impôts.txt"))
+
+		resolved, err := resolver.ResolveDocument(ctx, documentPath)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(resolved.InternalID).To(gomega.Equal(document.InternalID))
+	})
+
+	ginkgo.It("Should report the failing segment", func(ctx ginkgo.SpecContext) {
+		var pathErr *digiposte.PathError
+
+		_, err := digiposteClient.StatPath(ctx, base+"/missing/avis.txt")
+		gomega.Expect(err).To(gomega.MatchError(digiposte.ErrNotFound))
+		gomega.Expect(errors.As(err, &pathErr)).To(gomega.BeTrue())
+		gomega.Expect(pathErr.Segment).To(gomega.Equal("missing"))
+
+		_, err = digiposteClient.StatPath(ctx, base+"/sub/same.txt")
+		gomega.Expect(err).To(gomega.MatchError(digiposte.ErrAmbiguousPath))
+
+		_, err = digiposteClient.OpenPath(ctx, base+"/sub")
+		gomega.Expect(err).To(gomega.MatchError(digiposte.ErrNotDocument))
+
+		_, err = digiposteClient.NewResolver().ResolveFolder(ctx, base+"/avis.txt")
+		gomega.Expect(err).To(gomega.MatchError(digiposte.ErrNotFolder))
+	})
+
+	ginkgo.It("Should upload and open a document by path", func(ctx ginkgo.SpecContext) {
+		document, err := digiposteClient.UploadPath(ctx, base+"/sub/new.txt", strings.NewReader("new content"), digiposte.DocumentTypeBasic)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		documents = append(documents, document.InternalID)
+
+		gomega.Expect(document.Name).To(gomega.Equal("new.txt"))
+
+		_, err = digiposteClient.UploadPath(ctx, base+"/sub/new.txt", strings.NewReader("again"), digiposte.DocumentTypeBasic)
+		gomega.Expect(err).To(gomega.MatchError(digiposte.ErrConflict))
+
+		reader, err := digiposteClient.OpenPath(ctx, base+"/sub/new.txt")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		defer reader.Close()
+
+		gomega.Expect(io.ReadAll(reader)).To(gomega.Equal([]byte("new content")))
+	})
+
+	ginkgo.It("Should move, rename and trash by path", func(ctx ginkgo.SpecContext) {
+		gomega.Expect(digiposteClient.MovePath(ctx, base+"/avis.txt", base+"/sub")).To(gomega.Succeed())
+		gomega.Expect(digiposteClient.StatPath(ctx, base+"/sub/avis.txt")).ToNot(gomega.BeNil())
+
+		gomega.Expect(digiposteClient.MovePath(ctx, base+"/sub/avis.txt", base+"/renamed.txt")).To(gomega.Succeed())
+
+		entry, err := digiposteClient.StatPath(ctx, base+"/renamed.txt")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(entry.Document.Name).To(gomega.Equal("renamed.txt"))
+
+		gomega.Expect(digiposteClient.MovePath(ctx, base+"/renamed.txt", base+"/missing/avis.txt")).
+			To(gomega.MatchError(digiposte.ErrNotFound))
+
+		gomega.Expect(digiposteClient.TrashPath(ctx, "/")).To(gomega.MatchError(digiposte.ErrValidation))
+		gomega.Expect(digiposteClient.TrashPath(ctx, base+"/renamed.txt")).To(gomega.Succeed())
+
+		_, err = digiposteClient.StatPath(ctx, base+"/renamed.txt")
+		gomega.Expect(err).To(gomega.MatchError(digiposte.ErrNotFound))
+	})
+})
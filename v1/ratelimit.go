@@ -0,0 +1,210 @@
+package digiposte
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimit is the budget of requests sent to a server.
+type RateLimit struct {
+	// Rate is the maximum number of requests per second.
+	Rate rate.Limit
+
+	// Burst is the maximum number of requests sent at once. It must be at least 1, unless Rate is rate.Inf.
+	Burst int
+}
+
+// DefaultRateLimit returns a rate limit low enough to avoid being blocked by Digiposte.
+func DefaultRateLimit() RateLimit {
+	return RateLimit{
+		Rate:  rate.Every(1 * time.Second),
+		Burst: 5,
+	}
+}
+
+// WithRateLimit limits the requests sent to the API and to the document servers, with separate budgets.
+//
+// The limits adapt to the server: the rate is halved every time the server answers
+// 429 Too Many Requests, 503 Service Unavailable with a Retry-After header, or a
+// RateLimit-Remaining header of 0. It is restored progressively on successful responses.
+func WithRateLimit(api, document RateLimit) ClientOption {
+	return func(c *Client) {
+		documentURL, _ := url.Parse(c.documentURL)
+
+		c.rateLimiters = &rateLimiters{
+			documentURL: documentURL,
+			api:         newAdaptiveLimiter(api),
+			document:    newAdaptiveLimiter(document),
+		}
+	}
+}
+
+// RateLimits returns the current rates of the API and document servers.
+// It returns rate.Inf when the client is not rate limited.
+func (c *Client) RateLimits() (rate.Limit, rate.Limit) {
+	if c.rateLimiters == nil {
+		return rate.Inf, rate.Inf
+	}
+
+	return c.rateLimiters.api.limiter.Limit(), c.rateLimiters.document.limiter.Limit()
+}
+
+// send sends the request once, within the rate limit of its server.
+func (c *clientHelper) send(req *http.Request) (*http.Response, error) {
+	logger := c.log().With(slog.String("method", req.Method), slog.String("url", req.URL.String()))
+
+	var limiter *adaptiveLimiter
+
+	if c.rateLimiters != nil {
+		limiter = c.rateLimiters.forRequest(req)
+
+		if err := limiter.wait(req); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+
+	response, err := c.client.Do(req)
+	if err != nil {
+		logger.Debug("Request failed", slog.Any("error", err), slog.Duration("duration", time.Since(start)))
+
+		return nil, err //nolint:wrapcheck
+	}
+
+	logger.Debug("Request sent", slog.Int("status", response.StatusCode), slog.Duration("duration", time.Since(start)))
+
+	if isThrottled(response) {
+		logger.Warn("Throttled by the server", slog.Int("status", response.StatusCode))
+	}
+
+	if limiter != nil {
+		limiter.observe(response)
+	}
+
+	return response, nil
+}
+
+type rateLimiters struct {
+	// documentURL is nil if the document URL of the client is invalid.
+	documentURL *url.URL
+
+	api      *adaptiveLimiter
+	document *adaptiveLimiter
+}
+
+func (r *rateLimiters) forRequest(req *http.Request) *adaptiveLimiter {
+	if r.documentURL != nil && isUnder(req.URL, r.documentURL) {
+		return r.document
+	}
+
+	return r.api
+}
+
+// isUnder reports whether the URL is on the server of the base URL, and under its path.
+func isUnder(target, base *url.URL) bool {
+	if !strings.EqualFold(target.Scheme, base.Scheme) || !strings.EqualFold(target.Host, base.Host) {
+		return false
+	}
+
+	prefix := strings.TrimRight(base.Path, "/")
+
+	return target.Path == prefix || strings.HasPrefix(target.Path, prefix+"/")
+}
+
+const (
+	// minRateDivisor is the lowest fraction of the configured rate an adaptive limiter can reach.
+	minRateDivisor = 16
+	// recoveryDivisor is the fraction of the configured rate restored on every successful response.
+	recoveryDivisor = 20
+)
+
+// adaptiveLimiter is a rate limiter slowing down when the server shows throttling signs.
+type adaptiveLimiter struct {
+	limiter *rate.Limiter
+	// err is the reason the limit is invalid: every request fails with it.
+	err error
+
+	lock    sync.Mutex
+	maxRate rate.Limit
+	minRate rate.Limit
+}
+
+func newAdaptiveLimiter(limit RateLimit) *adaptiveLimiter {
+	var err error
+
+	if limit.Rate != rate.Inf && limit.Burst < 1 {
+		err = fmt.Errorf("%w: the burst of a rate limit must be at least 1, got %d", ErrValidation, limit.Burst)
+	}
+
+	return &adaptiveLimiter{
+		limiter: rate.NewLimiter(limit.Rate, limit.Burst),
+		err:     err,
+		lock:    sync.Mutex{},
+		maxRate: limit.Rate,
+		minRate: limit.Rate / minRateDivisor,
+	}
+}
+
+func (l *adaptiveLimiter) wait(req *http.Request) error {
+	if l.err != nil {
+		return l.err
+	}
+
+	if err := l.limiter.Wait(req.Context()); err != nil {
+		return fmt.Errorf("rate limited: %w", err)
+	}
+
+	return nil
+}
+
+// observe adjusts the rate according to the response.
+func (l *adaptiveLimiter) observe(response *http.Response) {
+	if l.maxRate == rate.Inf {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	current := l.limiter.Limit()
+
+	switch {
+	case isThrottled(response):
+		current /= 2
+		if current < l.minRate {
+			current = l.minRate
+		}
+
+	case response.StatusCode < http.StatusBadRequest && current < l.maxRate:
+		current += l.maxRate / recoveryDivisor
+		if current > l.maxRate {
+			current = l.maxRate
+		}
+
+	default:
+		return
+	}
+
+	l.limiter.SetLimit(current)
+}
+
+func isThrottled(response *http.Response) bool {
+	switch {
+	case response.StatusCode == http.StatusTooManyRequests:
+		return true
+	case response.StatusCode == http.StatusServiceUnavailable && response.Header.Get("Retry-After") != "":
+		return true
+	case response.Header.Get("RateLimit-Remaining") == "0", response.Header.Get("X-RateLimit-Remaining") == "0":
+		return true
+	default:
+		return false
+	}
+}
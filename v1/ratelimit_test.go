@@ -0,0 +1,161 @@
+package digiposte_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"golang.org/x/time/rate"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+	"github.com/holyhope/digiposte-go-sdk/v1/digipostetest"
+)
+
+var _ = ginkgo.Describe("RateLimit", func() {
+	var (
+		apiServer      *ghttp.Server
+		documentServer *ghttp.Server
+		client         *digiposte.Client
+	)
+
+	limit := digiposte.RateLimit{Rate: 1000, Burst: 10}
+
+	ginkgo.BeforeEach(func() {
+		apiServer = ghttp.NewServer()
+		ginkgo.DeferCleanup(apiServer.Close)
+
+		documentServer = ghttp.NewServer()
+		ginkgo.DeferCleanup(documentServer.Close)
+
+		client = digiposte.NewCustomClient(apiServer.URL(), documentServer.URL(), nil,
+			digiposte.WithRateLimit(limit, limit),
+		)
+	})
+
+	ginkgo.Context("Without rate limit", func() {
+		ginkgo.It("Should not limit the requests", func() {
+			api, document := digiposte.NewCustomClient(apiServer.URL(), documentServer.URL(), nil).RateLimits()
+			gomega.Expect(api).To(gomega.Equal(rate.Inf))
+			gomega.Expect(document).To(gomega.Equal(rate.Inf))
+		})
+	})
+
+	ginkgo.Context("With an invalid burst", func() {
+		ginkgo.It("Should reject the requests", func(ctx ginkgo.SpecContext) {
+			client := digiposte.NewCustomClient(apiServer.URL(), documentServer.URL(), nil,
+				digiposte.WithRateLimit(digiposte.RateLimit{Rate: 1000, Burst: 0}, limit),
+			)
+
+			_, err := client.ListFolders(ctx)
+			gomega.Expect(err).To(gomega.MatchError(digiposte.ErrValidation))
+			gomega.Expect(apiServer.ReceivedRequests()).To(gomega.BeEmpty())
+		})
+	})
+
+	ginkgo.Context("When a host only starts like the document server", func() {
+		ginkgo.It("Should use the API budget", func(ctx ginkgo.SpecContext) {
+			apiServer.AppendHandlers(ghttp.RespondWithJSONEncoded(
+				http.StatusOK,
+				&digiposte.SearchFoldersResult{},
+				http.Header{"Ratelimit-Remaining": []string{"0"}},
+			))
+
+			// The URL of the API server starts like this document URL, one digit of the port short.
+			documentURL := apiServer.URL()[:len(apiServer.URL())-1]
+
+			client := digiposte.NewCustomClient(apiServer.URL(), documentURL, nil, digiposte.WithRateLimit(limit, limit))
+
+			_, err := client.ListFolders(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			api, document := client.RateLimits()
+			gomega.Expect(api).To(gomega.BeEquivalentTo(limit.Rate / 2))
+			gomega.Expect(document).To(gomega.BeEquivalentTo(limit.Rate))
+		})
+	})
+
+	ginkgo.Context("When the token is refreshed", func() {
+		ginkgo.It("Should use the document budget", func(ctx ginkgo.SpecContext) {
+			documentServer.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodGet, "/rest/security/token"),
+				ghttp.RespondWithJSONEncoded(
+					http.StatusOK,
+					&digiposte.AccessToken{Token: "token", ExpiresAt: time.Now().Add(time.Hour), IsTokenConsolidated: true},
+					http.Header{"Ratelimit-Remaining": []string{"0"}},
+				),
+			))
+			apiServer.AppendHandlers(ghttp.RespondWithJSONEncoded(http.StatusOK, &digiposte.SearchFoldersResult{}))
+
+			fake := digipostetest.NewServer()
+			ginkgo.DeferCleanup(fake.Close)
+
+			client, err := digiposte.NewAuthenticatedClient(ctx, new(http.Client), &digiposte.Config{
+				APIURL:           apiServer.URL(),
+				DocumentURL:      documentServer.URL(),
+				LoginMethod:      fake.LoginMethod(),
+				Credentials:      nil,
+				SessionListener:  nil,
+				PreviousSession:  nil,
+				SessionStore:     nil,
+				SessionSaveError: nil,
+				ClientOptions:    []digiposte.ClientOption{digiposte.WithRateLimit(limit, limit)},
+			})
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			_, err = client.ListFolders(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			_, document := client.RateLimits()
+			gomega.Expect(document).To(gomega.BeEquivalentTo(limit.Rate / 2))
+		})
+	})
+
+	ginkgo.Context("When the API server answers 429", func() {
+		ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+			apiServer.AppendHandlers(ghttp.RespondWithJSONEncoded(http.StatusTooManyRequests, digiposte.RequestErrors{{
+				ErrorCode: "too_many_requests",
+				ErrorDesc: "slow down",
+			}}))
+
+			_, err := client.ListFolders(ctx)
+			gomega.Expect(err).To(gomega.HaveOccurred())
+		})
+
+		ginkgo.It("Should only tighten the API budget", func() {
+			api, document := client.RateLimits()
+			gomega.Expect(api).To(gomega.BeEquivalentTo(limit.Rate / 2))
+			gomega.Expect(document).To(gomega.BeEquivalentTo(limit.Rate))
+		})
+
+		ginkgo.It("Should restore the budget progressively", func(ctx ginkgo.SpecContext) {
+			apiServer.AppendHandlers(
+				ghttp.RespondWithJSONEncoded(http.StatusOK, &digiposte.SearchFoldersResult{}),
+			)
+
+			_, err := client.ListFolders(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			api, _ := client.RateLimits()
+			gomega.Expect(api).To(gomega.BeNumerically(">", limit.Rate/2))
+			gomega.Expect(api).To(gomega.BeNumerically("<", limit.Rate))
+		})
+	})
+
+	ginkgo.Context("When the API server has no remaining budget", func() {
+		ginkgo.It("Should tighten the API budget", func(ctx ginkgo.SpecContext) {
+			apiServer.AppendHandlers(ghttp.RespondWithJSONEncoded(
+				http.StatusOK,
+				&digiposte.SearchFoldersResult{},
+				http.Header{"Ratelimit-Remaining": []string{"0"}},
+			))
+
+			_, err := client.ListFolders(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			api, _ := client.RateLimits()
+			gomega.Expect(api).To(gomega.BeEquivalentTo(limit.Rate / 2))
+		})
+	})
+})
@@ -0,0 +1,293 @@
+package digiposte
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy describes how failed requests are retried.
+//
+// Idempotent requests (GET, HEAD, OPTIONS, TRACE, PUT, DELETE, and the document searches sent
+// with POST) are retried on connection failures and on the RetryableStatuses. Other requests,
+// such as uploads, are only retried on 429 Too Many Requests, because the server rejected them
+// before processing them.
+// When a retried request ends with an error, it is returned in a RetryError with the errors
+// of the previous attempts.
+// Requests with a body are only retried when the body can be replayed through GetBody.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between two attempts, including the Retry-After delays.
+	MaxBackoff time.Duration
+
+	// Multiplier is the factor applied to the delay after each attempt.
+	Multiplier float64
+
+	// Jitter is the fraction of the delay randomly removed, between 0 and 1.
+	Jitter float64
+
+	// RetryableStatuses are the HTTP statuses to retry.
+	// If nil, 429, 502, 503 and 504 are retried.
+	RetryableStatuses []int
+}
+
+// DefaultRetryPolicy returns the recommended retry policy.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:       4,
+		InitialBackoff:    500 * time.Millisecond,
+		MaxBackoff:        30 * time.Second,
+		Multiplier:        2,
+		Jitter:            0.2,
+		RetryableStatuses: nil,
+	}
+}
+
+// WithRetryPolicy retries the failed requests according to the policy.
+func WithRetryPolicy(policy *RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+//nolint:gochecknoglobals
+var defaultRetryableStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+func (p *RetryPolicy) retryableStatus(status int) bool {
+	statuses := p.RetryableStatuses
+	if statuses == nil {
+		statuses = defaultRetryableStatuses
+	}
+
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shouldRetry reports whether the attempt should be retried.
+func (p *RetryPolicy) shouldRetry(req *http.Request, response *http.Response, err error) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		return isIdempotent(req) && req.Context().Err() == nil && isTransientError(err)
+	}
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		return p.retryableStatus(response.StatusCode)
+	}
+
+	return isIdempotent(req) && p.retryableStatus(response.StatusCode)
+}
+
+// backoff returns the delay before the given retry, starting at 1.
+func (p *RetryPolicy) backoff(retry int, response *http.Response) time.Duration {
+	delay := time.Duration(float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(retry-1)))
+
+	if p.Jitter > 0 {
+		delay -= time.Duration(p.Jitter * rand.Float64() * float64(delay)) //nolint:gosec
+	}
+
+	if response != nil {
+		if retryAfter, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+			delay = retryAfter
+		}
+	}
+
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+
+	return delay
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+
+		return delay, true
+	}
+
+	return 0, false
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	marked, _ := req.Context().Value(idempotentKey{}).(bool)
+
+	return marked
+}
+
+// idempotentKey is the context key of the requests marked by markIdempotent.
+type idempotentKey struct{}
+
+// markIdempotent returns the request marked as safe to retry, such as a POST only reading data.
+func markIdempotent(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), idempotentKey{}, true))
+}
+
+func isTransientError(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// RetryError is returned when a request failed after several attempts.
+// It holds the error of every attempt.
+type RetryError struct {
+	Attempts []error
+}
+
+func (e *RetryError) Error() string {
+	strs := make([]string, 0, len(e.Attempts))
+
+	for i, err := range e.Attempts {
+		strs = append(strs, fmt.Sprintf("attempt %d: %v", i+1, err))
+	}
+
+	return fmt.Sprintf("gave up after %d attempts: %s", len(e.Attempts), strings.Join(strs, "; "))
+}
+
+func (e *RetryError) Unwrap() []error {
+	return e.Attempts
+}
+
+// do sends the request, retrying it according to the retry policy of the client.
+func (c *clientHelper) do(req *http.Request) (*http.Response, error) {
+	policy := c.retryPolicy
+	if policy == nil || policy.MaxAttempts <= 1 {
+		return c.send(req) //nolint:wrapcheck
+	}
+
+	var attempts []error
+
+	for attempt := 1; ; attempt++ {
+		response, err := c.send(req)
+
+		if !policy.shouldRetry(req, response, err) {
+			if len(attempts) > 0 && err == nil && response.StatusCode >= http.StatusBadRequest {
+				err = c.attemptError(response)
+			}
+
+			if err != nil && len(attempts) > 0 {
+				return nil, &RetryError{Attempts: append(attempts, err)}
+			}
+
+			return response, err //nolint:wrapcheck
+		}
+
+		if err == nil {
+			err = c.attemptError(response)
+		}
+
+		attempts = append(attempts, err)
+
+		if attempt >= policy.MaxAttempts {
+			return nil, &RetryError{Attempts: attempts}
+		}
+
+		delay := policy.backoff(attempt, response)
+
+		c.log().Warn("Retrying request",
+			slog.String("method", req.Method),
+			slog.String("url", req.URL.String()),
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay),
+			slog.Any("error", err),
+		)
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, &RetryError{Attempts: append(attempts, err)}
+		}
+
+		if req, err = rewind(req); err != nil {
+			return nil, &RetryError{Attempts: append(attempts, err)}
+		}
+	}
+}
+
+// attemptError consumes the response of a failed attempt and returns the matching error.
+func (c *clientHelper) attemptError(response *http.Response) error {
+	err := c.checkResponse(response)
+
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if closeErr := response.Body.Close(); closeErr != nil {
+		return &CloseBodyError{Err: closeErr, OriginalError: err}
+	}
+
+	return err
+}
+
+// rewind returns a copy of the request with a fresh body.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("get body: %w", err)
+	}
+
+	newReq := req.Clone(req.Context())
+	newReq.Body = body
+
+	return newReq, nil
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("context done: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
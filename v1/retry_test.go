@@ -0,0 +1,157 @@
+package digiposte_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+var _ = ginkgo.Describe("RetryPolicy", func() {
+	var (
+		server *ghttp.Server
+		client *digiposte.Client
+	)
+
+	unavailable := ghttp.RespondWithJSONEncoded(http.StatusServiceUnavailable, digiposte.RequestErrors{{
+		ErrorCode: "unavailable",
+		ErrorDesc: "try again later",
+	}})
+
+	ginkgo.BeforeEach(func() {
+		server = ghttp.NewServer()
+		ginkgo.DeferCleanup(server.Close)
+
+		client = digiposte.NewCustomClient(server.URL(), server.URL(), nil, digiposte.WithRetryPolicy(&digiposte.RetryPolicy{
+			MaxAttempts:       3,
+			InitialBackoff:    time.Millisecond,
+			MaxBackoff:        10 * time.Millisecond,
+			Multiplier:        2,
+			Jitter:            0.5,
+			RetryableStatuses: nil,
+		}))
+	})
+
+	ginkgo.Context("With an idempotent request", func() {
+		ginkgo.It("Should retry until it succeeds", func(ctx ginkgo.SpecContext) {
+			server.AppendHandlers(
+				unavailable,
+				unavailable,
+				ghttp.RespondWithJSONEncoded(http.StatusOK, &digiposte.SearchFoldersResult{Count: 1}),
+			)
+
+			result, err := client.ListFolders(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(result.Count).To(gomega.BeEquivalentTo(1))
+			gomega.Expect(server.ReceivedRequests()).To(gomega.HaveLen(3))
+		})
+
+		ginkgo.It("Should list every attempt when it gives up", func(ctx ginkgo.SpecContext) {
+			server.AppendHandlers(unavailable, unavailable, unavailable)
+
+			_, err := client.ListFolders(ctx)
+
+			var retryErr *digiposte.RetryError
+
+			gomega.Expect(errors.As(err, &retryErr)).To(gomega.BeTrue())
+			gomega.Expect(retryErr.Attempts).To(gomega.HaveLen(3))
+			gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("gave up after 3 attempts")))
+			gomega.Expect(server.ReceivedRequests()).To(gomega.HaveLen(3))
+		})
+
+		ginkgo.It("Should list the previous attempts when it ends with an error", func(ctx ginkgo.SpecContext) {
+			server.AppendHandlers(unavailable, unavailable, ghttp.RespondWithJSONEncoded(http.StatusNotFound,
+				digiposte.RequestErrors{{
+					ErrorCode: "not_found",
+					ErrorDesc: "Folder not found.",
+				}},
+			))
+
+			_, err := client.ListFolders(ctx)
+
+			var retryErr *digiposte.RetryError
+
+			gomega.Expect(errors.As(err, &retryErr)).To(gomega.BeTrue())
+			gomega.Expect(retryErr.Attempts).To(gomega.HaveLen(3))
+			gomega.Expect(retryErr.Attempts[2]).To(gomega.MatchError(gomega.ContainSubstring("Folder not found.")))
+			gomega.Expect(server.ReceivedRequests()).To(gomega.HaveLen(3))
+		})
+
+		ginkgo.It("Should retry searches", func(ctx ginkgo.SpecContext) {
+			server.AppendHandlers(
+				unavailable,
+				ghttp.CombineHandlers(
+					func(_ http.ResponseWriter, req *http.Request) {
+						gomega.Expect(req.Header).ToNot(gomega.HaveKey("X-Idempotency-Key"))
+					},
+					ghttp.VerifyJSONRepresenting(map[string]interface{}{
+						"folder_id": "",
+						"locations": []string{"INBOX", "SAFE"},
+					}),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, &digiposte.SearchDocumentsResult{}),
+				),
+			)
+
+			gomega.Expect(client.SearchDocuments(ctx, digiposte.RootFolderID)).ToNot(gomega.BeNil())
+			gomega.Expect(server.ReceivedRequests()).To(gomega.HaveLen(2))
+		})
+	})
+
+	ginkgo.Context("With a non-idempotent request", func() {
+		ginkgo.It("Should not retry on server errors", func(ctx ginkgo.SpecContext) {
+			server.AppendHandlers(unavailable)
+
+			_, err := client.CreateFolder(ctx, digiposte.RootFolderID, "folder")
+			gomega.Expect(err).To(gomega.HaveOccurred())
+			gomega.Expect(server.ReceivedRequests()).To(gomega.HaveLen(1))
+		})
+
+		ginkgo.It("Should replay the upload after a 429", func(ctx ginkgo.SpecContext) {
+			verifyUpload := func(_ http.ResponseWriter, req *http.Request) {
+				reader, err := req.MultipartReader()
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+				for {
+					part, err := reader.NextPart()
+					if err == io.EOF { //nolint:errorlint
+						break
+					}
+
+					gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+					if part.FormName() == "archive" {
+						gomega.Expect(io.ReadAll(part)).To(gomega.BeEquivalentTo("the content"))
+					}
+				}
+			}
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					verifyUpload,
+					ghttp.RespondWith(http.StatusTooManyRequests, nil, http.Header{"Retry-After": {"0"}}),
+				),
+				ghttp.CombineHandlers(
+					verifyUpload,
+					ghttp.RespondWithJSONEncoded(http.StatusOK, &digiposte.Document{InternalID: "id"}),
+				),
+			)
+
+			document, err := client.CreateDocument(ctx,
+				digiposte.RootFolderID,
+				"document.txt",
+				strings.NewReader("the content"),
+				digiposte.DocumentTypeBasic,
+			)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(document.InternalID).To(gomega.BeEquivalentTo("id"))
+			gomega.Expect(server.ReceivedRequests()).To(gomega.HaveLen(2))
+		})
+	})
+})
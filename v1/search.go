@@ -0,0 +1,260 @@
+package digiposte
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+)
+
+// Parameters of the search body set by the options below.
+const (
+	titleParam     = "title"
+	fullTextParam  = "fulltext"
+	startDateParam = "start_date"
+	endDateParam   = "end_date"
+	mimeTypesParam = "mimetypes"
+	minSizeParam   = "min_size"
+	maxSizeParam   = "max_size"
+	recursiveParam = "recursive"
+	sortParam      = "sort"
+	directionParam = "direction"
+	folderIDParam  = "folder_id"
+)
+
+// SortField is the field the search results are sorted by.
+type SortField string
+
+const (
+	SortByTitle SortField = "TITLE"
+	SortByDate  SortField = "DATE"
+	SortBySize  SortField = "SIZE"
+)
+
+// SortDirection is the order of the search results.
+type SortDirection string
+
+const (
+	Ascending  SortDirection = "ASC"
+	Descending SortDirection = "DESC"
+)
+
+// DocumentTitleContains returns only documents whose title contains the text, ignoring the case.
+func DocumentTitleContains(text string) DocumentSearchOption {
+	return func(body map[string]interface{}) {
+		body[titleParam] = text
+	}
+}
+
+// DocumentContentMatches returns only documents whose content matches the query.
+// The offer of the user must include the full content search, see Offer.HasFullContentSearchAbility.
+func DocumentContentMatches(query string) DocumentSearchOption {
+	return func(body map[string]interface{}) {
+		body[fullTextParam] = query
+	}
+}
+
+// DocumentCreatedBetween returns only documents created between start and end, both included.
+// A zero time leaves that side of the range open.
+func DocumentCreatedBetween(start, end time.Time) DocumentSearchOption {
+	return func(body map[string]interface{}) {
+		delete(body, startDateParam)
+		delete(body, endDateParam)
+
+		if !start.IsZero() {
+			body[startDateParam] = start.UTC().Format(timeFormat)
+		}
+
+		if !end.IsZero() {
+			body[endDateParam] = end.UTC().Format(timeFormat)
+		}
+	}
+}
+
+// DocumentWithMimeTypes returns only documents having one of the mime types, such as "application/pdf".
+func DocumentWithMimeTypes(mimeTypes ...string) DocumentSearchOption {
+	return func(body map[string]interface{}) {
+		body[mimeTypesParam] = mimeTypes
+	}
+}
+
+// DocumentSizeAtLeast returns only documents of at least the given size, in bytes.
+func DocumentSizeAtLeast(size int64) DocumentSearchOption {
+	return func(body map[string]interface{}) {
+		body[minSizeParam] = size
+	}
+}
+
+// DocumentSizeAtMost returns only documents of at most the given size, in bytes.
+func DocumentSizeAtMost(size int64) DocumentSearchOption {
+	return func(body map[string]interface{}) {
+		body[maxSizeParam] = size
+	}
+}
+
+// IncludeSubFolders also returns the documents of the sub-folders of the searched folder.
+func IncludeSubFolders() DocumentSearchOption {
+	return func(body map[string]interface{}) {
+		body[recursiveParam] = true
+	}
+}
+
+// SortDocumentsBy sorts the results, instead of the default ascending title order.
+func SortDocumentsBy(field SortField, direction SortDirection) DocumentSearchOption {
+	return func(body map[string]interface{}) {
+		body[sortParam] = field
+		body[directionParam] = direction
+	}
+}
+
+// validateSearch rejects the invalid options of a search body before it is sent.
+func validateSearch(body map[string]interface{}) error {
+	for _, check := range []func(map[string]interface{}) error{
+		validateSearchText,
+		validateSearchDates,
+		validateSearchMimeTypes,
+		validateSearchSizes,
+		validateSearchScope,
+		validateSearchOrder,
+	} {
+		if err := check(body); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateSearchText(body map[string]interface{}) error {
+	title, hasTitle := body[titleParam].(string)
+	query, hasQuery := body[fullTextParam].(string)
+
+	switch {
+	case hasTitle && hasQuery:
+		return &SearchOptionError{Reason: "the title and content queries cannot be combined"}
+	case hasTitle && strings.TrimSpace(title) == "":
+		return &SearchOptionError{Reason: "empty title query"}
+	case hasQuery && strings.TrimSpace(query) == "":
+		return &SearchOptionError{Reason: "empty content query"}
+	default:
+		return nil
+	}
+}
+
+func validateSearchDates(body map[string]interface{}) error {
+	start, hasStart := body[startDateParam].(string)
+	end, hasEnd := body[endDateParam].(string)
+
+	// The dates are formatted in UTC with a fixed width, so they sort like the times.
+	if hasStart && hasEnd && end < start {
+		return &SearchOptionError{Reason: "the creation date range ends before it starts"}
+	}
+
+	return nil
+}
+
+func validateSearchMimeTypes(body map[string]interface{}) error {
+	mimeTypes, ok := body[mimeTypesParam].([]string)
+	if !ok {
+		return nil
+	}
+
+	if len(mimeTypes) == 0 {
+		return &SearchOptionError{Reason: "no mime type"}
+	}
+
+	for _, mimeType := range mimeTypes {
+		mediaType, _, err := mime.ParseMediaType(mimeType)
+		if err != nil || !strings.Contains(mediaType, "/") {
+			return &SearchOptionError{Reason: fmt.Sprintf("invalid mime type %q", mimeType)}
+		}
+	}
+
+	return nil
+}
+
+func validateSearchSizes(body map[string]interface{}) error {
+	minSize, hasMin := body[minSizeParam].(int64)
+	maxSize, hasMax := body[maxSizeParam].(int64)
+
+	switch {
+	case hasMin && minSize < 0, hasMax && maxSize < 0:
+		return &SearchOptionError{Reason: "negative size"}
+	case hasMin && hasMax && maxSize < minSize:
+		return &SearchOptionError{Reason: "the maximum size is lower than the minimum size"}
+	default:
+		return nil
+	}
+}
+
+func validateSearchScope(body map[string]interface{}) error {
+	if _, hasFolder := body[folderIDParam]; !hasFolder && body[recursiveParam] == true {
+		return &SearchOptionError{Reason: "the sub-folders can only be included in the search of a folder"}
+	}
+
+	if size, ok := body[maxResultsParam].(int64); ok && size <= 0 {
+		return &SearchOptionError{Reason: "the page size must be positive"}
+	}
+
+	if index, ok := body[indexParam].(int64); ok && index < 0 {
+		return &SearchOptionError{Reason: "negative index"}
+	}
+
+	return nil
+}
+
+func validateSearchOrder(body map[string]interface{}) error {
+	if field, ok := body[sortParam].(SortField); ok {
+		switch field {
+		case SortByTitle, SortByDate, SortBySize:
+		default:
+			return &SearchOptionError{Reason: fmt.Sprintf("unknown sort field %q", field)}
+		}
+	}
+
+	if direction, ok := body[directionParam].(SortDirection); ok {
+		switch direction {
+		case Ascending, Descending:
+		default:
+			return &SearchOptionError{Reason: fmt.Sprintf("unknown sort direction %q", direction)}
+		}
+	}
+
+	return nil
+}
+
+// SearchOptionError is returned when the options of a search are invalid. It matches ErrValidation.
+type SearchOptionError struct {
+	Reason string
+}
+
+func (e *SearchOptionError) Error() string {
+	return "invalid search: " + e.Reason
+}
+
+func (e *SearchOptionError) Unwrap() error {
+	return ErrValidation
+}
+
+// checkFullContentSearch fails if the search needs the full content search and the offer does not include it.
+// The ability is cached by the client once found. Without it, the profile is checked again by the next search,
+// in case the offer was upgraded meanwhile.
+func (c *Client) checkFullContentSearch(ctx context.Context, body map[string]interface{}) error {
+	if _, ok := body[fullTextParam]; !ok || c.fullContentSearch.Load() {
+		return nil
+	}
+
+	profile, err := c.GetProfile(ctx, ProfileModeNoSpaceConsumption)
+	if err != nil {
+		return fmt.Errorf("get profile: %w", err)
+	}
+
+	if !profile.Offer.HasFullContentSearchAbility && !profile.Capabilities.HasFullContentSearchAbility {
+		return fmt.Errorf("%w: the offer does not include the full content search", ErrForbidden)
+	}
+
+	c.fullContentSearch.Store(true)
+
+	return nil
+}
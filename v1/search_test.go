@@ -0,0 +1,173 @@
+package digiposte_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+	"github.com/holyhope/digiposte-go-sdk/v1/digipostetest"
+)
+
+var _ = ginkgo.Describe("Search filters", func() {
+	var (
+		server *digipostetest.Server
+		client *digiposte.Client
+		folder *digiposte.Folder
+	)
+
+	ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+		server = digipostetest.NewServer()
+		ginkgo.DeferCleanup(server.Close)
+
+		var err error
+
+		client, err = digiposte.NewAuthenticatedClient(ctx, new(http.Client), server.Config())
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		folder = server.AddFolder(digiposte.RootFolderID, "Impôts")
+		sub := server.AddFolder(folder.InternalID, "2024")
+
+		server.AddDocument(folder.InternalID, "Avis 2023.pdf", []byte("%PDF-1.4 avis d'imposition"), digiposte.LocationSafe)
+		server.AddDocument(sub.InternalID, "avis 2024.txt", []byte("avis de taxe fonciere"), digiposte.LocationSafe)
+		server.AddDocument(sub.InternalID, "notes.txt", []byte("a longer note about the taxes"), digiposte.LocationSafe)
+	})
+
+	names := func(ctx ginkgo.SpecContext, options ...digiposte.DocumentSearchOption) []string {
+		documents, err := client.SearchDocumentsIter(ctx, folder.InternalID, options...).All()
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		result := make([]string, 0, len(documents))
+		for _, document := range documents {
+			result = append(result, document.Name)
+		}
+
+		return result
+	}
+
+	ginkgo.It("Should search the sub-folders", func(ctx ginkgo.SpecContext) {
+		gomega.Expect(names(ctx)).To(gomega.Equal([]string{"Avis 2023.pdf"}))
+		gomega.Expect(names(ctx, digiposte.IncludeSubFolders())).
+			To(gomega.Equal([]string{"Avis 2023.pdf", "avis 2024.txt", "notes.txt"}))
+	})
+
+	ginkgo.It("Should filter by title, mime type, size and date", func(ctx ginkgo.SpecContext) {
+		gomega.Expect(names(ctx, digiposte.IncludeSubFolders(), digiposte.DocumentTitleContains("AVIS"))).
+			To(gomega.Equal([]string{"Avis 2023.pdf", "avis 2024.txt"}))
+		gomega.Expect(names(ctx, digiposte.IncludeSubFolders(), digiposte.DocumentWithMimeTypes("text/plain"))).
+			To(gomega.Equal([]string{"avis 2024.txt", "notes.txt"}))
+		gomega.Expect(names(ctx, digiposte.IncludeSubFolders(), digiposte.DocumentSizeAtLeast(22),
+			digiposte.DocumentSizeAtMost(26))).To(gomega.Equal([]string{"Avis 2023.pdf"}))
+		gomega.Expect(names(ctx, digiposte.IncludeSubFolders(),
+			digiposte.DocumentCreatedBetween(time.Time{}, time.Now().Add(-time.Hour)))).To(gomega.BeEmpty())
+		gomega.Expect(names(ctx, digiposte.IncludeSubFolders(),
+			digiposte.DocumentCreatedBetween(time.Now().Add(-time.Hour), time.Time{}))).To(gomega.HaveLen(3))
+	})
+
+	ginkgo.It("Should sort the results", func(ctx ginkgo.SpecContext) {
+		gomega.Expect(names(ctx, digiposte.IncludeSubFolders(),
+			digiposte.SortDocumentsBy(digiposte.SortBySize, digiposte.Descending))).
+			To(gomega.Equal([]string{"notes.txt", "Avis 2023.pdf", "avis 2024.txt"}))
+	})
+
+	ginkgo.It("Should search the content only if the offer allows it", func(ctx ginkgo.SpecContext) {
+		_, err := client.SearchDocuments(ctx, folder.InternalID, digiposte.DocumentContentMatches("taxe"))
+		gomega.Expect(err).To(gomega.MatchError(digiposte.ErrForbidden))
+
+		server.EnableFullContentSearch()
+
+		gomega.Expect(names(ctx, digiposte.IncludeSubFolders(), digiposte.DocumentContentMatches("TAXE"))).
+			To(gomega.Equal([]string{"avis 2024.txt", "notes.txt"}))
+	})
+
+	ginkgo.DescribeTable("Should reject invalid options before sending the request",
+		func(ctx ginkgo.SpecContext, options ...digiposte.DocumentSearchOption) {
+			server.Close()
+
+			_, err := client.SearchDocuments(ctx, folder.InternalID, options...)
+
+			var optionErr *digiposte.SearchOptionError
+
+			gomega.Expect(err).To(gomega.BeAssignableToTypeOf(optionErr))
+			gomega.Expect(err).To(gomega.MatchError(digiposte.ErrValidation))
+		},
+		ginkgo.Entry("title and content", digiposte.DocumentTitleContains("a"), digiposte.DocumentContentMatches("b")),
+		ginkgo.Entry("empty title", digiposte.DocumentTitleContains(" ")),
+		ginkgo.Entry("reversed dates", digiposte.DocumentCreatedBetween(time.Now(), time.Now().Add(-time.Hour))),
+		ginkgo.Entry("invalid mime type", digiposte.DocumentWithMimeTypes("pdf")),
+		ginkgo.Entry("reversed sizes", digiposte.DocumentSizeAtLeast(10), digiposte.DocumentSizeAtMost(5)),
+		ginkgo.Entry("negative size", digiposte.DocumentSizeAtMost(-1)),
+		ginkgo.Entry("unknown sort", digiposte.SortDocumentsBy("NAME", digiposte.Ascending)),
+		ginkgo.Entry("empty page", digiposte.WithPageSize(0)),
+	)
+
+	ginkgo.It("Should not search the sub-folders of the trash", func(ctx ginkgo.SpecContext) {
+		_, err := client.GetTrashedDocuments(ctx, digiposte.IncludeSubFolders())
+		gomega.Expect(err).To(gomega.MatchError(digiposte.ErrValidation))
+	})
+})
+
+var _ = ginkgo.Describe("Search requests", func() {
+	var (
+		server *ghttp.Server
+		client *digiposte.Client
+	)
+
+	ginkgo.BeforeEach(func() {
+		server = ghttp.NewServer()
+		ginkgo.DeferCleanup(server.Close)
+
+		client = digiposte.NewCustomClient(server.URL(), server.URL(), nil)
+	})
+
+	ginkgo.It("Should send the creation dates in UTC", func(ctx ginkgo.SpecContext) {
+		paris := time.FixedZone("CEST", 2*60*60)
+
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest(http.MethodPost, "/v3/documents/search"),
+			ghttp.VerifyJSONRepresenting(map[string]interface{}{
+				"folder_id":  "folder",
+				"locations":  []string{"INBOX", "SAFE"},
+				"start_date": "2024-01-02T01:04:05Z",
+				"end_date":   "2024-06-30T22:00:00Z",
+			}),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, &digiposte.SearchDocumentsResult{}),
+		))
+
+		_, err := client.SearchDocuments(ctx, "folder", digiposte.DocumentCreatedBetween(
+			time.Date(2024, time.January, 2, 3, 4, 5, 999, paris),
+			time.Date(2024, time.July, 1, 0, 0, 0, 0, paris),
+		))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+	})
+
+	ginkgo.It("Should check the full content search once", func(ctx ginkgo.SpecContext) {
+		profile := new(digiposte.Profile)
+		profile.Offer.HasFullContentSearchAbility = true
+
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodGet, "/v4/profile"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, profile),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodPost, "/v3/documents/search"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, &digiposte.SearchDocumentsResult{}),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodPost, "/v3/documents/search"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, &digiposte.SearchDocumentsResult{}),
+			),
+		)
+
+		for i := 0; i < 2; i++ {
+			_, err := client.SearchDocuments(ctx, "folder", digiposte.DocumentContentMatches("taxe"))
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		}
+
+		gomega.Expect(server.ReceivedRequests()).To(gomega.HaveLen(3))
+	})
+})
@@ -0,0 +1,239 @@
+package digiposte
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/holyhope/digiposte-go-sdk/internal/utils"
+)
+
+// SessionStore persists the session between runs.
+type SessionStore interface {
+	// Load returns the saved session, or nil if there is none.
+	Load(ctx context.Context) (*Session, error)
+	// Save replaces the saved session.
+	Save(ctx context.Context, session *Session) error
+	// Delete removes the saved session. It does nothing if there is none.
+	Delete(ctx context.Context) error
+}
+
+// WithSessionStore sets the store cleared by Logout.
+// NewAuthenticatedClient sets it from Config.SessionStore.
+func WithSessionStore(store SessionStore) ClientOption {
+	return func(c *Client) {
+		c.sessionStore = store
+	}
+}
+
+// FileSessionStore saves the session as JSON in a file readable only by the user.
+type FileSessionStore struct {
+	path string
+}
+
+// NewFileSessionStore returns a store saving the session to the given file.
+// The parent directories are created as needed.
+func NewFileSessionStore(path string) *FileSessionStore {
+	return &FileSessionStore{path: path}
+}
+
+// Load implements SessionStore.
+func (s *FileSessionStore) Load(_ context.Context) (*Session, error) {
+	content, err := readSessionFile(s.path)
+	if content == nil || err != nil {
+		return nil, err
+	}
+
+	session := new(Session)
+	if err := json.Unmarshal(content, session); err != nil {
+		return nil, fmt.Errorf("decode session %q: %w", s.path, err)
+	}
+
+	return session, nil
+}
+
+// Save implements SessionStore.
+func (s *FileSessionStore) Save(_ context.Context, session *Session) error {
+	content, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("encode session: %w", err)
+	}
+
+	return writeSessionFile(s.path, content)
+}
+
+// Delete implements SessionStore.
+func (s *FileSessionStore) Delete(_ context.Context) error {
+	return deleteSessionFile(s.path)
+}
+
+// ErrSessionDecrypt is returned when an encrypted session cannot be decrypted,
+// usually because the passphrase is wrong.
+var ErrSessionDecrypt = errors.New("decrypt session")
+
+// Parameters of the key derivation, see https://pkg.go.dev/golang.org/x/crypto/scrypt.
+const (
+	scryptN       = 1 << 15
+	scryptR       = 8
+	scryptP       = 1
+	scryptKeySize = 32
+	scryptSaltLen = 16
+)
+
+// EncryptedFileSessionStore saves the session in a file encrypted with AES-256-GCM.
+// The key is derived from a passphrase with scrypt and a random salt stored in the file.
+type EncryptedFileSessionStore struct {
+	path       string
+	passphrase []byte
+}
+
+// NewEncryptedFileSessionStore returns a store saving the session to the given file,
+// encrypted with the passphrase.
+func NewEncryptedFileSessionStore(path string, passphrase []byte) *EncryptedFileSessionStore {
+	return &EncryptedFileSessionStore{path: path, passphrase: passphrase}
+}
+
+// encryptedSession is the content of the file of an EncryptedFileSessionStore.
+type encryptedSession struct {
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func (s *EncryptedFileSessionStore) aead(salt []byte) (cipher.AEAD, error) { //nolint:ireturn
+	key, err := scrypt.Key(s.passphrase, salt, scryptN, scryptR, scryptP, scryptKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("new cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("new GCM: %w", err)
+	}
+
+	return aead, nil
+}
+
+// Load implements SessionStore.
+func (s *EncryptedFileSessionStore) Load(_ context.Context) (*Session, error) {
+	content, err := readSessionFile(s.path)
+	if content == nil || err != nil {
+		return nil, err
+	}
+
+	encrypted := new(encryptedSession)
+	if err := json.Unmarshal(content, encrypted); err != nil {
+		return nil, fmt.Errorf("decode session %q: %w", s.path, err)
+	}
+
+	aead, err := s.aead(encrypted.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(encrypted.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: invalid nonce", ErrSessionDecrypt)
+	}
+
+	plaintext, err := aead.Open(nil, encrypted.Nonce, encrypted.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSessionDecrypt, err)
+	}
+
+	session := new(Session)
+	if err := json.Unmarshal(plaintext, session); err != nil {
+		return nil, fmt.Errorf("decode session: %w", err)
+	}
+
+	return session, nil
+}
+
+// Save implements SessionStore. A new salt and nonce are generated each time.
+func (s *EncryptedFileSessionStore) Save(_ context.Context, session *Session) error {
+	plaintext, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("encode session: %w", err)
+	}
+
+	salt := make([]byte, scryptSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("generate salt: %w", err)
+	}
+
+	aead, err := s.aead(salt)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("generate nonce: %w", err)
+	}
+
+	content, err := json.Marshal(&encryptedSession{
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, nil),
+	})
+	if err != nil {
+		return fmt.Errorf("encode encrypted session: %w", err)
+	}
+
+	return writeSessionFile(s.path, content)
+}
+
+// Delete implements SessionStore.
+func (s *EncryptedFileSessionStore) Delete(_ context.Context) error {
+	return deleteSessionFile(s.path)
+}
+
+// readSessionFile returns the content of the file, or nil if it does not exist.
+func readSessionFile(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read session: %w", err)
+	}
+
+	return content, nil
+}
+
+// writeSessionFile writes the file atomically, readable only by the user.
+func writeSessionFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create session directory: %w", err)
+	}
+
+	err := utils.WriteFile(path, filepath.Base(path)+".*", time.Time{}, utils.WriteContent(content))
+	if err != nil {
+		return fmt.Errorf("write session file: %w", err)
+	}
+
+	return nil
+}
+
+func deleteSessionFile(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove session: %w", err)
+	}
+
+	return nil
+}
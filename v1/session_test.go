@@ -0,0 +1,172 @@
+package digiposte_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"golang.org/x/oauth2"
+
+	"github.com/holyhope/digiposte-go-sdk/login"
+	"github.com/holyhope/digiposte-go-sdk/v1"
+	"github.com/holyhope/digiposte-go-sdk/v1/digipostetest"
+)
+
+var _ = ginkgo.Describe("SessionStore", func() {
+	var (
+		dir     string
+		session *digiposte.Session
+	)
+
+	ginkgo.BeforeEach(func() {
+		dir = ginkgo.GinkgoT().TempDir()
+		session = &digiposte.Session{
+			Token: &oauth2.Token{
+				AccessToken:  "access-token",
+				TokenType:    "Bearer",
+				RefreshToken: "",
+				Expiry:       time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC),
+			},
+			Cookies: []*http.Cookie{{Name: "SESSION", Value: "secret"}},
+		}
+	})
+
+	testStore := func(newStore func(path string) digiposte.SessionStore) {
+		var (
+			path  string
+			store digiposte.SessionStore
+		)
+
+		ginkgo.BeforeEach(func() {
+			path = filepath.Join(dir, "sub", "session.json")
+			store = newStore(path)
+		})
+
+		ginkgo.It("Should load nothing when there is no session", func(ctx ginkgo.SpecContext) {
+			gomega.Expect(store.Load(ctx)).To(gomega.BeNil())
+			gomega.Expect(store.Delete(ctx)).To(gomega.Succeed())
+		})
+
+		ginkgo.It("Should save, load and delete the session", func(ctx ginkgo.SpecContext) {
+			gomega.Expect(store.Save(ctx, session)).To(gomega.Succeed())
+
+			info, err := os.Stat(path)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(info.Mode().Perm()).To(gomega.Equal(os.FileMode(0o600)))
+
+			loaded, err := store.Load(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(loaded.Token.AccessToken).To(gomega.Equal(session.Token.AccessToken))
+			gomega.Expect(loaded.Token.Expiry).To(gomega.BeTemporally("==", session.Token.Expiry))
+			gomega.Expect(loaded.Cookies).To(gomega.HaveLen(1))
+			gomega.Expect(loaded.Cookies[0].Value).To(gomega.Equal("secret"))
+
+			gomega.Expect(store.Delete(ctx)).To(gomega.Succeed())
+			gomega.Expect(path).ToNot(gomega.BeAnExistingFile())
+		})
+	}
+
+	ginkgo.Describe("FileSessionStore", func() {
+		testStore(func(path string) digiposte.SessionStore {
+			return digiposte.NewFileSessionStore(path)
+		})
+	})
+
+	ginkgo.Describe("EncryptedFileSessionStore", func() {
+		testStore(func(path string) digiposte.SessionStore {
+			return digiposte.NewEncryptedFileSessionStore(path, []byte("passphrase"))
+		})
+
+		ginkgo.It("Should not store the session in clear", func(ctx ginkgo.SpecContext) {
+			path := filepath.Join(dir, "session.json")
+
+			gomega.Expect(digiposte.NewEncryptedFileSessionStore(path, []byte("passphrase")).Save(ctx, session)).To(gomega.Succeed())
+
+			content, err := os.ReadFile(path)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(string(content)).ToNot(gomega.ContainSubstring("access-token"))
+			gomega.Expect(string(content)).ToNot(gomega.ContainSubstring("secret"))
+		})
+
+		ginkgo.It("Should fail with the wrong passphrase", func(ctx ginkgo.SpecContext) {
+			path := filepath.Join(dir, "session.json")
+
+			gomega.Expect(digiposte.NewEncryptedFileSessionStore(path, []byte("passphrase")).Save(ctx, session)).To(gomega.Succeed())
+
+			_, err := digiposte.NewEncryptedFileSessionStore(path, []byte("wrong")).Load(ctx)
+			gomega.Expect(err).To(gomega.MatchError(digiposte.ErrSessionDecrypt))
+		})
+	})
+
+	ginkgo.Describe("Config.SessionStore", func() {
+		var (
+			server *digipostetest.Server
+			store  *digiposte.FileSessionStore
+			path   string
+		)
+
+		ginkgo.BeforeEach(func() {
+			server = digipostetest.NewServer()
+			ginkgo.DeferCleanup(server.Close)
+
+			path = filepath.Join(dir, "session.json")
+			store = digiposte.NewFileSessionStore(path)
+		})
+
+		newClient := func(ctx context.Context, method login.Method) *digiposte.Client {
+			config := server.Config()
+			config.LoginMethod = method
+			config.SessionStore = store
+
+			client, err := digiposte.NewAuthenticatedClient(ctx, new(http.Client), config)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			return client
+		}
+
+		ginkgo.It("Should save the session, reuse it and clear it on logout", func(ctx ginkgo.SpecContext) {
+			client := newClient(ctx, server.LoginMethod())
+
+			_, err := client.GetProfile(ctx, digiposte.ProfileModeNoSpaceConsumption)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(path).To(gomega.BeAnExistingFile())
+
+			client = newClient(ctx, login.MethodFunc(func(context.Context, *login.Credentials) (*oauth2.Token, []*http.Cookie, error) {
+				ginkgo.Fail("should reuse the saved session")
+
+				return nil, nil, nil
+			}))
+
+			_, err = client.GetProfile(ctx, digiposte.ProfileModeNoSpaceConsumption)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			gomega.Expect(client.Logout(ctx)).To(gomega.Succeed())
+			gomega.Expect(path).ToNot(gomega.BeAnExistingFile())
+		})
+
+		ginkgo.It("Should report the sessions that cannot be saved", func(ctx ginkgo.SpecContext) {
+			notDir := filepath.Join(dir, "not-a-directory")
+			gomega.Expect(os.WriteFile(notDir, nil, 0o600)).To(gomega.Succeed())
+
+			var saveErrs []error
+
+			config := server.Config()
+			config.PreviousSession = new(digiposte.Session)
+			config.SessionStore = digiposte.NewFileSessionStore(filepath.Join(notDir, "session.json"))
+			config.SessionSaveError = func(err error) {
+				saveErrs = append(saveErrs, err)
+			}
+
+			client, err := digiposte.NewAuthenticatedClient(ctx, new(http.Client), config)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			_, err = client.GetProfile(ctx, digiposte.ProfileModeNoSpaceConsumption)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred(), "the client works without saving the session")
+			gomega.Expect(saveErrs).To(gomega.HaveLen(1))
+		})
+	})
+})
@@ -32,6 +32,24 @@ const timeFormat = `2006-01-02T15:04:05Z`
 
 // Share creates a share for a specific time period, with a title and a security code.
 func (c *Client) CreateShare(ctx context.Context, startDate, endDate time.Time, title, code string) (*Share, error) {
+	bodyBytes, err := json.Marshal(shareBody(startDate, endDate, title, code))
+	if err != nil {
+		return nil, fmt.Errorf("marshal body: %w", err)
+	}
+
+	req, err := c.apiRequest(ctx, http.MethodPost, "/v3/share", bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+
+	share := new(Share)
+
+	return share, c.call(req, share)
+}
+
+// shareBody returns the body of the requests creating or updating a share.
+// A zero end date means the share does not expire, and an empty code means there is no security code.
+func shareBody(startDate, endDate time.Time, title, code string) map[string]interface{} {
 	body := map[string]interface{}{
 		"start_date": startDate.UTC().Format(timeFormat),
 		"title":      title,
@@ -45,12 +63,25 @@ func (c *Client) CreateShare(ctx context.Context, startDate, endDate time.Time,
 		body["security_code"] = code
 	}
 
-	bodyBytes, err := json.Marshal(body)
+	return body
+}
+
+// UpdateShare replaces the time period, the title and the security code of a share,
+// with the same rules as CreateShare.
+func (c *Client) UpdateShare(
+	ctx context.Context,
+	shareID ShareID,
+	startDate, endDate time.Time,
+	title, code string,
+) (*Share, error) {
+	bodyBytes, err := json.Marshal(shareBody(startDate, endDate, title, code))
 	if err != nil {
 		return nil, fmt.Errorf("marshal body: %w", err)
 	}
 
-	req, err := c.apiRequest(ctx, http.MethodPost, "/v3/share", bytes.NewReader(bodyBytes))
+	endpoint := sharePrefix + url.PathEscape(string(shareID))
+
+	req, err := c.apiRequest(ctx, http.MethodPut, endpoint, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return nil, fmt.Errorf("new request: %w", err)
 	}
@@ -60,6 +91,46 @@ func (c *Client) CreateShare(ctx context.Context, startDate, endDate time.Time,
 	return share, c.call(req, share)
 }
 
+// ExtendShare postpones the end of a share, keeping its other settings.
+// The new end date must be after the current one: a share without end date cannot be extended.
+func (c *Client) ExtendShare(ctx context.Context, shareID ShareID, endDate time.Time) (*Share, error) {
+	share, err := c.GetShare(ctx, shareID)
+	if err != nil {
+		return nil, fmt.Errorf("get share: %w", err)
+	}
+
+	if share.EndDate.IsZero() {
+		return nil, fmt.Errorf("%w: the share never expires", ErrValidation)
+	}
+
+	if !endDate.After(share.EndDate) {
+		return nil, fmt.Errorf("%w: the new end date %s is not after %s",
+			ErrValidation, endDate.UTC().Format(timeFormat), share.EndDate.UTC().Format(timeFormat))
+	}
+
+	return c.UpdateShare(ctx, shareID, share.StartDate, endDate, share.Title, share.SecurityCode)
+}
+
+// AddShareRecipients sends the share by email to the given addresses.
+// They are added to the RecipientMails of the share.
+func (c *Client) AddShareRecipients(ctx context.Context, shareID ShareID, recipientMails []string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"recipient_mails": recipientMails,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal body: %w", err)
+	}
+
+	endpoint := sharePrefix + url.PathEscape(string(shareID)) + "/recipients"
+
+	req, err := c.apiRequest(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+
+	return c.call(req, nil)
+}
+
 // SetShareDocuments adds a document to a share.
 func (c *Client) SetShareDocuments(ctx context.Context, shareID ShareID, documentIDs []DocumentID) error {
 	body, err := json.Marshal(map[string]interface{}{
@@ -79,6 +150,25 @@ func (c *Client) SetShareDocuments(ctx context.Context, shareID ShareID, documen
 	return c.call(req, nil)
 }
 
+// RemoveShareDocuments removes documents from a share. The other documents stay shared.
+func (c *Client) RemoveShareDocuments(ctx context.Context, shareID ShareID, documentIDs []DocumentID) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"ids": documentIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal body: %w", err)
+	}
+
+	endpoint := sharePrefix + url.PathEscape(string(shareID)) + "/documents"
+
+	req, err := c.apiRequest(ctx, http.MethodDelete, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+
+	return c.call(req, nil)
+}
+
 // ShareResult represents a share.
 type ShareResult struct {
 	SenderShares []Share `json:"senderShares"`
@@ -1,12 +1,14 @@
 package digiposte_test
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
 
 	"github.com/holyhope/digiposte-go-sdk/v1"
+	"github.com/holyhope/digiposte-go-sdk/v1/digipostetest"
 )
 
 var _ = ginkgo.Describe("Share", func() {
@@ -31,4 +33,96 @@ var _ = ginkgo.Describe("Share", func() {
 			})
 		})
 	})
+
+	// The lifecycle runs against the fake server, so that no email is sent.
+	ginkgo.Context("Lifecycle", func() {
+		var (
+			client    *digiposte.Client
+			share     *digiposte.Share
+			documents []*digiposte.Document
+			start     time.Time
+		)
+
+		ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+			server := digipostetest.NewServer()
+			ginkgo.DeferCleanup(server.Close)
+
+			var err error
+
+			client, err = digiposte.NewAuthenticatedClient(ctx, new(http.Client), server.Config())
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			documents = []*digiposte.Document{
+				server.AddDocument(digiposte.RootFolderID, "avis.pdf", []byte("avis"), digiposte.LocationSafe),
+				server.AddDocument(digiposte.RootFolderID, "facture.pdf", []byte("facture"), digiposte.LocationSafe),
+			}
+
+			start = time.Now().UTC().Truncate(time.Second)
+
+			share, err = client.CreateShare(ctx, start, start.Add(time.Hour), "taxes", "1234")
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		})
+
+		ginkgo.It("Should update the share", func(ctx ginkgo.SpecContext) {
+			updated, err := client.UpdateShare(ctx, share.InternalID, start, start.Add(2*time.Hour), "new title", "")
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(updated.Title).To(gomega.Equal("new title"))
+			gomega.Expect(updated.SecurityCode).To(gomega.BeEmpty())
+			gomega.Expect(updated.EndDate).To(gomega.BeTemporally("==", start.Add(2*time.Hour)))
+
+			_, err = client.UpdateShare(ctx, share.InternalID, start, start.Add(-time.Hour), "new title", "")
+			gomega.Expect(err).To(gomega.MatchError(digiposte.ErrValidation))
+		})
+
+		ginkgo.It("Should extend the share", func(ctx ginkgo.SpecContext) {
+			extended, err := client.ExtendShare(ctx, share.InternalID, start.Add(24*time.Hour))
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(extended.EndDate).To(gomega.BeTemporally("==", start.Add(24*time.Hour)))
+			gomega.Expect(extended.Title).To(gomega.Equal("taxes"))
+			gomega.Expect(extended.SecurityCode).To(gomega.Equal("1234"))
+
+			_, err = client.ExtendShare(ctx, share.InternalID, start.Add(time.Hour))
+			gomega.Expect(err).To(gomega.MatchError(digiposte.ErrValidation))
+		})
+
+		ginkgo.It("Should not shorten a share without end date", func(ctx ginkgo.SpecContext) {
+			unlimited, err := client.CreateShare(ctx, start, time.Time{}, "forever", "")
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			_, err = client.ExtendShare(ctx, unlimited.InternalID, start.Add(24*time.Hour))
+			gomega.Expect(err).To(gomega.MatchError(digiposte.ErrValidation))
+
+			got, err := client.GetShare(ctx, unlimited.InternalID)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(got.EndDate.IsZero()).To(gomega.BeTrue())
+		})
+
+		ginkgo.It("Should add recipients", func(ctx ginkgo.SpecContext) {
+			gomega.Expect(client.AddShareRecipients(ctx, share.InternalID, []string{"a@example.com"})).To(gomega.Succeed())
+			gomega.Expect(client.AddShareRecipients(ctx, share.InternalID, []string{"a@example.com", "b@example.com"})).
+				To(gomega.Succeed())
+
+			got, err := client.GetShare(ctx, share.InternalID)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(got.RecipientMails).To(gomega.Equal([]string{"a@example.com", "b@example.com"}))
+
+			gomega.Expect(client.AddShareRecipients(ctx, share.InternalID, []string{"not a mail"})).
+				To(gomega.MatchError(digiposte.ErrValidation))
+		})
+
+		ginkgo.It("Should remove documents", func(ctx ginkgo.SpecContext) {
+			gomega.Expect(client.SetShareDocuments(ctx, share.InternalID, []digiposte.DocumentID{
+				documents[0].InternalID, documents[1].InternalID,
+			})).To(gomega.Succeed())
+
+			gomega.Expect(client.RemoveShareDocuments(ctx, share.InternalID, []digiposte.DocumentID{
+				documents[0].InternalID,
+			})).To(gomega.Succeed())
+
+			result, err := client.GetShareDocuments(ctx, share.InternalID)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(result.Documents).To(gomega.HaveLen(1))
+			gomega.Expect(result.Documents[0].InternalID).To(gomega.Equal(documents[1].InternalID))
+		})
+	})
 })
@@ -0,0 +1,305 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/holyhope/digiposte-go-sdk/internal/utils"
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+var errMissingFolder = errors.New("missing remote folder")
+
+// ActionError is the failure of an action of a plan.
+type ActionError struct {
+	Action Action
+	Err    error
+}
+
+func (e *ActionError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Action.String(), e.Err)
+}
+
+func (e *ActionError) Unwrap() error {
+	return e.Err
+}
+
+// Apply applies the actions of a plan returned by Plan, then saves the state.
+// The failed actions do not stop the others: their errors are joined as *ActionError.
+// A failed action is planned again on the next run.
+func (s *Syncer) Apply(ctx context.Context, plan *Plan) error {
+	applier := &applier{
+		syncer:  s,
+		plan:    plan,
+		folders: make(map[string]digiposte.FolderID, len(plan.remoteFolders)),
+		state:   newState(),
+	}
+
+	for dir, folderID := range plan.remoteFolders {
+		applier.folders[dir] = folderID
+	}
+
+	applier.keepUntouched()
+
+	var errs []error
+
+	for _, action := range plan.Actions {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err) //nolint:wrapcheck
+
+			break
+		}
+
+		if err := applier.apply(ctx, action); err != nil {
+			errs = append(errs, &ActionError{Action: action, Err: err})
+
+			applier.keepPrevious(action)
+		}
+	}
+
+	if err := s.saveState(applier.state); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+type applier struct {
+	syncer  *Syncer
+	plan    *Plan
+	folders map[string]digiposte.FolderID
+	state   *State
+}
+
+// keepUntouched records the files present on both sides and not involved in any action.
+func (a *applier) keepUntouched() {
+	touched := make(map[string]struct{}, len(a.plan.Actions))
+
+	for _, action := range a.plan.Actions {
+		touched[action.Path] = struct{}{}
+
+		if action.NewPath != "" {
+			touched[action.NewPath] = struct{}{}
+		}
+
+		if action.Kind == ActionConflict {
+			a.keepPrevious(action)
+		}
+	}
+
+	for filePath, local := range a.plan.local {
+		document, ok := a.plan.remote[filePath]
+		if _, isTouched := touched[filePath]; isTouched || !ok {
+			continue
+		}
+
+		a.record(filePath, local, document)
+	}
+}
+
+// keepPrevious keeps the previous state of the files of an action not applied.
+func (a *applier) keepPrevious(action Action) {
+	for _, filePath := range []string{action.Path, action.NewPath} {
+		if state, ok := a.plan.state.Files[filePath]; ok {
+			a.state.Files[filePath] = state
+		}
+	}
+}
+
+func (a *applier) record(filePath string, local localFile, document *digiposte.Document) {
+	a.state.Files[filePath] = FileState{
+		DocumentID: document.InternalID,
+		Size:       local.size,
+		ModTime:    local.modTime,
+		CreatedAt:  document.CreatedAt,
+	}
+}
+
+func (a *applier) localPath(filePath string) string {
+	return filepath.Join(a.syncer.localDir, filepath.FromSlash(filePath))
+}
+
+func (a *applier) folder(dir string) (digiposte.FolderID, error) {
+	folderID, ok := a.folders[dir]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", errMissingFolder, dir)
+	}
+
+	return folderID, nil
+}
+
+func (a *applier) apply(ctx context.Context, action Action) error {
+	switch action.Kind {
+	case ActionCreateFolder:
+		return a.createFolder(ctx, action)
+	case ActionMove:
+		return a.move(ctx, action)
+	case ActionUpload:
+		return a.upload(ctx, action)
+	case ActionDownload:
+		return a.download(ctx, action)
+	case ActionTrash:
+		return a.trash(ctx, action)
+	case ActionConflict:
+	}
+
+	return nil
+}
+
+func (a *applier) createFolder(ctx context.Context, action Action) error {
+	if action.Side == SideLocal {
+		if err := os.MkdirAll(a.localPath(action.Path), 0o750); err != nil {
+			return fmt.Errorf("create directory: %w", err)
+		}
+
+		return nil
+	}
+
+	parentID, err := a.folder(path.Dir(action.Path))
+	if err != nil {
+		return err
+	}
+
+	folder, err := a.syncer.client.CreateFolder(ctx, parentID, path.Base(action.Path))
+	if err != nil {
+		return fmt.Errorf("create folder: %w", err)
+	}
+
+	a.folders[action.Path] = folder.InternalID
+
+	return nil
+}
+
+func (a *applier) move(ctx context.Context, action Action) error {
+	document := action.Document
+
+	if action.Side == SideLocal {
+		if err := os.Rename(a.localPath(action.Path), a.localPath(action.NewPath)); err != nil {
+			return fmt.Errorf("rename: %w", err)
+		}
+
+		a.record(action.NewPath, a.plan.local[action.Path], document)
+
+		return nil
+	}
+
+	if path.Dir(action.Path) != path.Dir(action.NewPath) {
+		folderID, err := a.folder(path.Dir(action.NewPath))
+		if err != nil {
+			return err
+		}
+
+		if err := a.syncer.client.Move(ctx, folderID, []digiposte.DocumentID{document.InternalID}, nil); err != nil {
+			return fmt.Errorf("move document: %w", err)
+		}
+	}
+
+	if name := path.Base(action.NewPath); name != document.Name {
+		renamed, err := a.syncer.client.RenameDocument(ctx, document.InternalID, name)
+		if err != nil {
+			return fmt.Errorf("rename document: %w", err)
+		}
+
+		document = renamed
+	}
+
+	a.record(action.NewPath, a.plan.local[action.NewPath], document)
+
+	return nil
+}
+
+func (a *applier) upload(ctx context.Context, action Action) error {
+	folderID, err := a.folder(path.Dir(action.Path))
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(a.localPath(action.Path))
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("stat: %w", err)
+	}
+
+	// Keep the name of the replaced document, which may differ from its path.
+	name := path.Base(action.Path)
+	if action.Document != nil {
+		name = action.Document.Name
+	}
+
+	document, err := a.syncer.client.CreateDocument(ctx, folderID, name, file, digiposte.DocumentTypeBasic)
+	if err != nil {
+		return fmt.Errorf("upload: %w", err)
+	}
+
+	a.record(action.Path, localFile{size: info.Size(), modTime: info.ModTime()}, document)
+
+	if action.Document != nil {
+		if err := a.syncer.client.Trash(ctx, []digiposte.DocumentID{action.Document.InternalID}, nil); err != nil {
+			return fmt.Errorf("trash replaced document: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (a *applier) download(ctx context.Context, action Action) (finalErr error) { //nolint:nonamedreturns
+	target := a.localPath(action.Path)
+
+	stream, err := a.syncer.client.DocumentStream(ctx, action.Document.InternalID)
+	if err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+
+	defer func() {
+		if err := stream.Close(); err != nil && finalErr == nil {
+			finalErr = fmt.Errorf("close stream: %w", err)
+		}
+	}()
+
+	err = utils.WriteFile(target, ignoredPrefix+"download-*", action.Document.CreatedAt, func(w io.Writer) error {
+		if _, err := io.Copy(w, stream); err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return fmt.Errorf("stat: %w", err)
+	}
+
+	a.record(action.Path, localFile{size: info.Size(), modTime: info.ModTime()}, action.Document)
+
+	return nil
+}
+
+func (a *applier) trash(ctx context.Context, action Action) error {
+	if action.Side == SideLocal {
+		if err := os.Remove(a.localPath(action.Path)); err != nil {
+			return fmt.Errorf("remove: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := a.syncer.client.Trash(ctx, []digiposte.DocumentID{action.Document.InternalID}, nil); err != nil {
+		return fmt.Errorf("trash: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,553 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+// ActionKind is the kind of an action of a plan.
+type ActionKind int
+
+const (
+	// ActionCreateFolder creates a folder.
+	ActionCreateFolder ActionKind = iota
+	// ActionMove moves a file from Path to NewPath.
+	ActionMove
+	// ActionUpload uploads a local file. If Document is set, it is trashed once replaced.
+	ActionUpload
+	// ActionDownload downloads a document.
+	ActionDownload
+	// ActionTrash moves a document to the trash, or removes a local file.
+	ActionTrash
+	// ActionConflict reports a file changed on both sides. It is not applied.
+	ActionConflict
+)
+
+func (k ActionKind) String() string {
+	switch k {
+	case ActionCreateFolder:
+		return "create-folder"
+	case ActionMove:
+		return "move"
+	case ActionUpload:
+		return "upload"
+	case ActionDownload:
+		return "download"
+	case ActionTrash:
+		return "trash"
+	case ActionConflict:
+		return "conflict"
+	default:
+		return fmt.Sprintf("ActionKind(%d)", int(k))
+	}
+}
+
+// Side is where an action is applied.
+type Side int
+
+const (
+	// SideLocal is the local directory.
+	SideLocal Side = iota
+	// SideRemote is the remote folder.
+	SideRemote
+)
+
+func (s Side) String() string {
+	if s == SideLocal {
+		return "local"
+	}
+
+	return "remote"
+}
+
+// Action is a step of a plan.
+// Paths are slash-separated and relative to the synchronized directory and folder.
+type Action struct {
+	Kind    ActionKind
+	Side    Side
+	Path    string
+	NewPath string
+
+	// Document is the remote document involved, if any.
+	Document *digiposte.Document
+}
+
+func (a *Action) String() string {
+	if a.Kind == ActionMove {
+		return fmt.Sprintf("%s %s %s -> %s", a.Side, a.Kind, a.Path, a.NewPath)
+	}
+
+	return fmt.Sprintf("%s %s %s", a.Side, a.Kind, a.Path)
+}
+
+// Plan is the list of actions needed to synchronize both sides.
+type Plan struct {
+	Actions []Action
+
+	state         *State
+	local         map[string]localFile
+	remote        map[string]*digiposte.Document
+	remoteFolders map[string]digiposte.FolderID
+}
+
+type localFile struct {
+	size    int64
+	modTime time.Time
+}
+
+func (f localFile) matches(state FileState) bool {
+	return f.size == state.Size && f.modTime.Equal(state.ModTime)
+}
+
+// Plan compares both sides and returns the actions to apply. It does not change anything.
+func (s *Syncer) Plan(ctx context.Context) (*Plan, error) {
+	state, err := s.loadState()
+	if err != nil {
+		return nil, err
+	}
+
+	local, localDirs, err := s.scanLocal()
+	if err != nil {
+		return nil, err
+	}
+
+	remote, remoteFolders, err := s.scanRemote(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	planner := &planner{
+		push:      s.mode != ModePull,
+		pull:      s.mode != ModePush,
+		mode:      s.mode,
+		conflicts: s.conflicts,
+		state:     state.Files,
+		local:     local,
+		remote:    remote,
+		handled:   make(map[string]struct{}),
+		actions:   nil,
+	}
+
+	planner.planMoves()
+	planner.planFiles()
+	planner.planFolders(localDirs, remoteFolders)
+
+	sort.SliceStable(planner.actions, func(i, j int) bool {
+		if planner.actions[i].Kind != planner.actions[j].Kind {
+			return planner.actions[i].Kind < planner.actions[j].Kind
+		}
+
+		return planner.actions[i].Path < planner.actions[j].Path
+	})
+
+	return &Plan{
+		Actions:       planner.actions,
+		state:         state,
+		local:         local,
+		remote:        remote,
+		remoteFolders: remoteFolders,
+	}, nil
+}
+
+// scanLocal returns the regular files and the directories of the local directory.
+func (s *Syncer) scanLocal() (map[string]localFile, map[string]struct{}, error) {
+	files := make(map[string]localFile)
+	dirs := make(map[string]struct{})
+
+	err := filepath.WalkDir(s.localDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(s.localDir, filePath)
+		if err != nil {
+			return fmt.Errorf("relative path: %w", err)
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		switch {
+		case rel == ".":
+			return nil
+		case strings.HasPrefix(entry.Name(), ignoredPrefix), filePath == s.stateFile:
+			return nil
+		case entry.IsDir():
+			dirs[rel] = struct{}{}
+
+			return nil
+		case !entry.Type().IsRegular():
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("stat: %w", err)
+		}
+
+		files[rel] = localFile{size: info.Size(), modTime: info.ModTime()}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("scan %q: %w", s.localDir, err)
+	}
+
+	return files, dirs, nil
+}
+
+// scanRemote returns the documents and the folders of the remote folder,
+// named like in the file system returned by Client.FS.
+func (s *Syncer) scanRemote(ctx context.Context) (map[string]*digiposte.Document, map[string]digiposte.FolderID, error) {
+	documents := make(map[string]*digiposte.Document)
+	folders := map[string]digiposte.FolderID{".": s.remoteID}
+
+	err := fs.WalkDir(s.client.FS(ctx, s.remoteID), ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("stat: %w", err)
+		}
+
+		switch sys := info.Sys().(type) {
+		case *digiposte.Document:
+			documents[filePath] = sys
+		case *digiposte.Folder:
+			if filePath != "." {
+				folders[filePath] = sys.InternalID
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("scan remote folder: %w", err)
+	}
+
+	return documents, folders, nil
+}
+
+type planner struct {
+	push, pull bool
+	mode       Mode
+	conflicts  ConflictPolicy
+
+	state  map[string]FileState
+	local  map[string]localFile
+	remote map[string]*digiposte.Document
+
+	handled map[string]struct{}
+	actions []Action
+}
+
+func (p *planner) add(kind ActionKind, side Side, filePath string, document *digiposte.Document) {
+	p.actions = append(p.actions, Action{
+		Kind:     kind,
+		Side:     side,
+		Path:     filePath,
+		NewPath:  "",
+		Document: document,
+	})
+	p.handled[filePath] = struct{}{}
+}
+
+func (p *planner) move(side Side, from, to string, document *digiposte.Document) {
+	p.actions = append(p.actions, Action{
+		Kind:     ActionMove,
+		Side:     side,
+		Path:     from,
+		NewPath:  to,
+		Document: document,
+	})
+	p.handled[from] = struct{}{}
+	p.handled[to] = struct{}{}
+}
+
+func (p *planner) isHandled(filePath string) bool {
+	_, ok := p.handled[filePath]
+
+	return ok
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// planMoves detects the files renamed or moved on one side since the last run.
+func (p *planner) planMoves() {
+	remoteByID := make(map[digiposte.DocumentID]string, len(p.remote))
+
+	for filePath, document := range p.remote {
+		remoteByID[document.InternalID] = filePath
+	}
+
+	for _, from := range sortedKeys(p.state) {
+		state := p.state[from]
+
+		if document, ok := p.remote[from]; ok && document.InternalID == state.DocumentID {
+			p.planLocalMove(from, state, document)
+
+			continue
+		}
+
+		// The document is no longer at its previous remote path.
+		to, ok := remoteByID[state.DocumentID]
+		if !ok || p.isHandled(to) || p.isHandled(from) {
+			continue
+		}
+
+		local, ok := p.local[from]
+		if _, exists := p.local[to]; exists || !ok || !local.matches(state) {
+			continue
+		}
+
+		if p.pull {
+			p.move(SideLocal, from, to, p.remote[to])
+		} else {
+			p.move(SideRemote, to, from, p.remote[to])
+		}
+	}
+}
+
+// planLocalMove detects a local file moved since the last run, while the document did not change.
+func (p *planner) planLocalMove(from string, state FileState, document *digiposte.Document) {
+	if !p.push || p.isHandled(from) {
+		return
+	}
+
+	if _, ok := p.local[from]; ok {
+		return
+	}
+
+	for _, to := range sortedKeys(p.local) {
+		_, known := p.state[to]
+		_, onRemote := p.remote[to]
+
+		if known || onRemote || p.isHandled(to) || !p.local[to].matches(state) {
+			continue
+		}
+
+		p.move(SideRemote, from, to, document)
+
+		return
+	}
+}
+
+// planFiles compares each file with its state.
+func (p *planner) planFiles() {
+	paths := make(map[string]struct{})
+
+	for filePath := range p.local {
+		paths[filePath] = struct{}{}
+	}
+
+	for filePath := range p.remote {
+		paths[filePath] = struct{}{}
+	}
+
+	for filePath := range p.state {
+		paths[filePath] = struct{}{}
+	}
+
+	for _, filePath := range sortedKeys(paths) {
+		if p.isHandled(filePath) {
+			continue
+		}
+
+		local, hasLocal := p.local[filePath]
+		document, hasRemote := p.remote[filePath]
+		state, hasState := p.state[filePath]
+
+		switch {
+		case hasLocal && hasRemote:
+			p.planBoth(filePath, local, document, state, hasState)
+		case hasLocal:
+			p.planLocalOnly(filePath, local, state, hasState)
+		case hasRemote:
+			p.planRemoteOnly(filePath, document, state, hasState)
+		}
+	}
+}
+
+func (p *planner) planBoth(
+	filePath string,
+	local localFile,
+	document *digiposte.Document,
+	state FileState,
+	hasState bool,
+) {
+	var localChanged, remoteChanged bool
+
+	if hasState {
+		localChanged = !local.matches(state)
+		remoteChanged = document.InternalID != state.DocumentID
+	} else {
+		// Never synchronized: consider the file is the same if the sizes match.
+		localChanged = local.size != document.Size
+		remoteChanged = localChanged
+	}
+
+	switch {
+	case !localChanged && !remoteChanged:
+	case localChanged && !remoteChanged, !localChanged && remoteChanged && p.mode == ModePush:
+		if p.push {
+			p.add(ActionUpload, SideRemote, filePath, document)
+		} else {
+			p.add(ActionDownload, SideLocal, filePath, document)
+		}
+	case !localChanged && remoteChanged:
+		p.add(ActionDownload, SideLocal, filePath, document)
+	default:
+		p.planConflict(filePath, local, document)
+	}
+}
+
+func (p *planner) planConflict(filePath string, local localFile, document *digiposte.Document) {
+	policy := p.conflicts
+
+	switch p.mode {
+	case ModePush:
+		policy = ConflictLocalWins
+	case ModePull:
+		policy = ConflictRemoteWins
+	case ModeBidirectional:
+	}
+
+	if policy == ConflictNewestWins {
+		policy = ConflictRemoteWins
+		if local.modTime.After(document.CreatedAt) {
+			policy = ConflictLocalWins
+		}
+	}
+
+	switch policy {
+	case ConflictLocalWins:
+		p.add(ActionUpload, SideRemote, filePath, document)
+	case ConflictRemoteWins:
+		p.add(ActionDownload, SideLocal, filePath, document)
+	case ConflictSkip, ConflictNewestWins:
+		p.add(ActionConflict, SideLocal, filePath, document)
+	}
+}
+
+func (p *planner) planLocalOnly(filePath string, local localFile, state FileState, hasState bool) {
+	switch {
+	case !hasState:
+		// New local file.
+		if p.push {
+			p.add(ActionUpload, SideRemote, filePath, nil)
+		}
+	case p.mode == ModePush:
+		// The document was removed, but the local directory is the source.
+		p.add(ActionUpload, SideRemote, filePath, nil)
+	case !local.matches(state):
+		// The document was removed, but the local file changed since: keep it.
+		if p.push {
+			p.add(ActionUpload, SideRemote, filePath, nil)
+		}
+	default:
+		p.add(ActionTrash, SideLocal, filePath, nil)
+	}
+}
+
+func (p *planner) planRemoteOnly(filePath string, document *digiposte.Document, state FileState, hasState bool) {
+	switch {
+	case !hasState:
+		// New document.
+		if p.pull {
+			p.add(ActionDownload, SideLocal, filePath, document)
+		}
+	case p.mode == ModePull:
+		// The local file was removed, but the remote folder is the source.
+		p.add(ActionDownload, SideLocal, filePath, document)
+	case document.InternalID != state.DocumentID:
+		// The local file was removed, but the document changed since: keep it.
+		if p.pull {
+			p.add(ActionDownload, SideLocal, filePath, document)
+		}
+	default:
+		p.add(ActionTrash, SideRemote, filePath, document)
+	}
+}
+
+// planFolders creates the folders missing on each side,
+// including the parents of the files to upload or download.
+func (p *planner) planFolders(localDirs map[string]struct{}, remoteFolders map[string]digiposte.FolderID) {
+	localMissing := make(map[string]struct{})
+	remoteMissing := make(map[string]struct{})
+
+	addParents := func(missing map[string]struct{}, filePath string, exists func(string) bool) {
+		for dir := path.Dir(filePath); dir != "."; dir = path.Dir(dir) {
+			if !exists(dir) {
+				missing[dir] = struct{}{}
+			}
+		}
+	}
+
+	localExists := func(dir string) bool {
+		_, ok := localDirs[dir]
+
+		return ok
+	}
+
+	remoteExists := func(dir string) bool {
+		_, ok := remoteFolders[dir]
+
+		return ok
+	}
+
+	if p.push {
+		for dir := range localDirs {
+			addParents(remoteMissing, dir+"/.", remoteExists)
+		}
+	}
+
+	if p.pull {
+		for dir := range remoteFolders {
+			if dir != "." {
+				addParents(localMissing, dir+"/.", localExists)
+			}
+		}
+	}
+
+	for _, action := range p.actions {
+		target := action.Path
+		if action.Kind == ActionMove {
+			target = action.NewPath
+		}
+
+		switch {
+		case action.Kind == ActionConflict, action.Kind == ActionTrash:
+		case action.Side == SideRemote:
+			addParents(remoteMissing, target, remoteExists)
+		case action.Side == SideLocal:
+			addParents(localMissing, target, localExists)
+		}
+	}
+
+	for _, dir := range sortedKeys(localMissing) {
+		p.actions = append(p.actions, Action{Kind: ActionCreateFolder, Side: SideLocal, Path: dir, NewPath: "", Document: nil})
+	}
+
+	for _, dir := range sortedKeys(remoteMissing) {
+		p.actions = append(p.actions, Action{Kind: ActionCreateFolder, Side: SideRemote, Path: dir, NewPath: "", Document: nil})
+	}
+}
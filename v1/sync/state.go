@@ -0,0 +1,81 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/holyhope/digiposte-go-sdk/internal/utils"
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+// stateVersion is the version of the format of the state file.
+const stateVersion = 1
+
+var errStateVersion = errors.New("unsupported state version")
+
+// State is the content of the state file: the files synchronized by the last run.
+type State struct {
+	Version int                  `json:"version"`
+	Files   map[string]FileState `json:"files"`
+}
+
+// FileState is a file present on both sides after the last run.
+type FileState struct {
+	DocumentID digiposte.DocumentID `json:"document_id"`
+	Size       int64                `json:"size"`
+	ModTime    time.Time            `json:"mod_time"`
+	CreatedAt  time.Time            `json:"created_at"`
+}
+
+func newState() *State {
+	return &State{
+		Version: stateVersion,
+		Files:   make(map[string]FileState),
+	}
+}
+
+// loadState reads the state file. A missing file is an empty state.
+func (s *Syncer) loadState() (*State, error) {
+	content, err := os.ReadFile(s.stateFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return newState(), nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read state: %w", err)
+	}
+
+	state := newState()
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("decode state %q: %w", s.stateFile, err)
+	}
+
+	if state.Version != stateVersion {
+		return nil, fmt.Errorf("%w: %d", errStateVersion, state.Version)
+	}
+
+	if state.Files == nil {
+		state.Files = make(map[string]FileState)
+	}
+
+	return state, nil
+}
+
+// saveState writes the state file atomically.
+func (s *Syncer) saveState(state *State) error {
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode state: %w", err)
+	}
+
+	err = utils.WriteFile(s.stateFile, ignoredPrefix+"state-*", time.Time{}, utils.WriteContent(content))
+	if err != nil {
+		return fmt.Errorf("write state file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+// Package sync synchronizes a local directory with a folder of the Digiposte safe.
+//
+// A Syncer compares both sides using the name, the size and the dates of the files,
+// and produces a Plan of actions. The plan can be inspected before being applied.
+// A state file records the result of the last synchronization, so that local and remote
+// changes, renames and deletions can be told apart on the next run.
+//
+// Folders are created on both sides as needed, but never deleted.
+package sync
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+// Mode is the direction of the synchronization.
+type Mode int
+
+const (
+	// ModeBidirectional propagates the changes of each side to the other one.
+	ModeBidirectional Mode = iota
+	// ModePush makes the remote folder match the local directory.
+	ModePush
+	// ModePull makes the local directory match the remote folder.
+	ModePull
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeBidirectional:
+		return "bidirectional"
+	case ModePush:
+		return "push"
+	case ModePull:
+		return "pull"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// ConflictPolicy tells what to do with a file changed on both sides since the last synchronization.
+// It is only used in bidirectional mode: the push and pull modes always favor their source.
+type ConflictPolicy int
+
+const (
+	// ConflictSkip leaves both sides untouched and reports the conflict in the plan.
+	ConflictSkip ConflictPolicy = iota
+	// ConflictLocalWins uploads the local file, replacing the remote document.
+	ConflictLocalWins
+	// ConflictRemoteWins downloads the remote document, replacing the local file.
+	ConflictRemoteWins
+	// ConflictNewestWins keeps the side with the most recent date:
+	// the modification time of the local file, or the creation date of the remote document.
+	ConflictNewestWins
+)
+
+// DefaultStateFileName is the name of the state file, in the local directory.
+// The files whose name starts with ".digiposte-" are ignored by the synchronization.
+const DefaultStateFileName = ignoredPrefix + "sync.json"
+
+const ignoredPrefix = ".digiposte-"
+
+// Syncer synchronizes a local directory with a remote folder.
+type Syncer struct {
+	client    *digiposte.Client
+	localDir  string
+	remoteID  digiposte.FolderID
+	mode      Mode
+	conflicts ConflictPolicy
+	stateFile string
+}
+
+// Option configures a Syncer.
+type Option func(s *Syncer)
+
+// WithMode sets the direction of the synchronization. The default is ModeBidirectional.
+func WithMode(mode Mode) Option {
+	return func(s *Syncer) {
+		s.mode = mode
+	}
+}
+
+// WithConflictPolicy sets how conflicts are resolved. The default is ConflictSkip.
+func WithConflictPolicy(policy ConflictPolicy) Option {
+	return func(s *Syncer) {
+		s.conflicts = policy
+	}
+}
+
+// WithStateFile sets the file the state is saved to.
+// The default is DefaultStateFileName in the local directory.
+func WithStateFile(path string) Option {
+	return func(s *Syncer) {
+		s.stateFile = path
+	}
+}
+
+// New returns a Syncer for the given local directory and remote folder.
+func New(client *digiposte.Client, localDir string, remoteID digiposte.FolderID, options ...Option) *Syncer {
+	syncer := &Syncer{
+		client:    client,
+		localDir:  localDir,
+		remoteID:  remoteID,
+		mode:      ModeBidirectional,
+		conflicts: ConflictSkip,
+		stateFile: filepath.Join(localDir, DefaultStateFileName),
+	}
+
+	for _, option := range options {
+		option(syncer)
+	}
+
+	return syncer
+}
+
+// Sync computes the plan and applies it.
+// The plan is returned even when some actions failed.
+func (s *Syncer) Sync(ctx context.Context) (*Plan, error) {
+	plan, err := s.Plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, s.Apply(ctx, plan)
+}
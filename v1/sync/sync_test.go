@@ -0,0 +1,261 @@
+package sync_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/gstruct"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+	"github.com/holyhope/digiposte-go-sdk/v1/digipostetest"
+	"github.com/holyhope/digiposte-go-sdk/v1/sync"
+)
+
+var _ = ginkgo.Describe("Syncer", func() {
+	var (
+		server *digipostetest.Server
+		client *digiposte.Client
+		root   *digiposte.Folder
+		dir    string
+	)
+
+	ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+		server = digipostetest.NewServer()
+		ginkgo.DeferCleanup(server.Close)
+
+		var err error
+
+		client, err = digiposte.NewAuthenticatedClient(ctx, new(http.Client), server.Config())
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		root = server.AddFolder(digiposte.RootFolderID, "sync")
+		dir = ginkgo.GinkgoT().TempDir()
+	})
+
+	writeFile := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		gomega.Expect(os.MkdirAll(filepath.Dir(path), 0o700)).To(gomega.Succeed())
+		gomega.Expect(os.WriteFile(path, []byte(content), 0o600)).To(gomega.Succeed())
+	}
+
+	readFile := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		return string(content)
+	}
+
+	remoteContent := func(ctx context.Context, document *digiposte.Document) string {
+		content, _, err := client.DocumentContent(ctx, document.InternalID)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		data, err := io.ReadAll(content)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		return string(data)
+	}
+
+	remoteDocuments := func(ctx context.Context, folderID digiposte.FolderID) []*digiposte.Document {
+		result, err := client.SearchDocuments(ctx, folderID)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		return result.Documents
+	}
+
+	kinds := func(plan *sync.Plan) []string {
+		result := make([]string, 0, len(plan.Actions))
+
+		for _, action := range plan.Actions {
+			result = append(result, action.String())
+		}
+
+		return result
+	}
+
+	ginkgo.Describe("Push", func() {
+		ginkgo.It("Should upload the local files and create the folders", func(ctx ginkgo.SpecContext) {
+			writeFile("a.txt", "alpha")
+			writeFile("taxes/2024/b.txt", "bravo")
+
+			syncer := sync.New(client, dir, root.InternalID, sync.WithMode(sync.ModePush))
+
+			plan, err := syncer.Sync(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(kinds(plan)).To(gomega.Equal([]string{
+				"remote create-folder taxes",
+				"remote create-folder taxes/2024",
+				"remote upload a.txt",
+				"remote upload taxes/2024/b.txt",
+			}))
+
+			documents := remoteDocuments(ctx, root.InternalID)
+			gomega.Expect(documents).To(gomega.ConsistOf(
+				gstruct.PointTo(gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+					"Name": gomega.Equal("a.txt"),
+				})),
+			))
+			gomega.Expect(remoteContent(ctx, documents[0])).To(gomega.Equal("alpha"))
+
+			plan, err = syncer.Plan(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(plan.Actions).To(gomega.BeEmpty())
+		})
+	})
+
+	ginkgo.Describe("Pull", func() {
+		ginkgo.It("Should download the documents", func(ctx ginkgo.SpecContext) {
+			sub := server.AddFolder(root.InternalID, "bills")
+			server.AddDocument(sub.InternalID, "c.txt", []byte("charlie"), digiposte.LocationSafe)
+
+			syncer := sync.New(client, dir, root.InternalID, sync.WithMode(sync.ModePull))
+
+			plan, err := syncer.Sync(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(kinds(plan)).To(gomega.Equal([]string{
+				"local create-folder bills",
+				"local download bills/c.txt",
+			}))
+			gomega.Expect(readFile("bills/c.txt")).To(gomega.Equal("charlie"))
+			gomega.Expect(filepath.Join(dir, sync.DefaultStateFileName)).To(gomega.BeAnExistingFile())
+
+			plan, err = syncer.Plan(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(plan.Actions).To(gomega.BeEmpty())
+		})
+	})
+
+	ginkgo.Describe("Bidirectional", func() {
+		var syncer *sync.Syncer
+
+		ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+			writeFile("a.txt", "alpha")
+			server.AddDocument(root.InternalID, "b.txt", []byte("bravo"), digiposte.LocationSafe)
+
+			syncer = sync.New(client, dir, root.InternalID)
+
+			_, err := syncer.Sync(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(readFile("b.txt")).To(gomega.Equal("bravo"))
+		})
+
+		ginkgo.It("Should not change anything when planning", func(ctx ginkgo.SpecContext) {
+			writeFile("new.txt", "new")
+
+			plan, err := syncer.Plan(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(kinds(plan)).To(gomega.Equal([]string{"remote upload new.txt"}))
+
+			gomega.Expect(remoteDocuments(ctx, root.InternalID)).To(gomega.HaveLen(2))
+		})
+
+		ginkgo.It("Should move the document renamed locally", func(ctx ginkgo.SpecContext) {
+			before := remoteDocuments(ctx, root.InternalID)
+
+			gomega.Expect(os.MkdirAll(filepath.Join(dir, "archive"), 0o700)).To(gomega.Succeed())
+			gomega.Expect(os.Rename(filepath.Join(dir, "a.txt"), filepath.Join(dir, "archive", "z.txt"))).To(gomega.Succeed())
+
+			plan, err := syncer.Sync(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(kinds(plan)).To(gomega.Equal([]string{
+				"remote create-folder archive",
+				"remote move a.txt -> archive/z.txt",
+			}))
+
+			folders, err := client.ListFolders(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			var archive *digiposte.Folder
+
+			for _, folder := range folders.Folders {
+				if folder.InternalID == root.InternalID {
+					gomega.Expect(folder.Folders).To(gomega.HaveLen(1))
+					archive = folder.Folders[0]
+				}
+			}
+
+			gomega.Expect(archive).ToNot(gomega.BeNil())
+
+			moved := remoteDocuments(ctx, archive.InternalID)
+			gomega.Expect(moved).To(gomega.HaveLen(1))
+			gomega.Expect(moved[0].Name).To(gomega.Equal("z.txt"))
+			gomega.Expect(before).To(gomega.ContainElement(gstruct.PointTo(gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+				"InternalID": gomega.Equal(moved[0].InternalID),
+			}))))
+		})
+
+		ginkgo.It("Should remove the local file of a trashed document", func(ctx ginkgo.SpecContext) {
+			for _, document := range remoteDocuments(ctx, root.InternalID) {
+				if document.Name == "b.txt" {
+					gomega.Expect(client.Trash(ctx, []digiposte.DocumentID{document.InternalID}, nil)).To(gomega.Succeed())
+				}
+			}
+
+			plan, err := syncer.Sync(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(kinds(plan)).To(gomega.Equal([]string{"local trash b.txt"}))
+			gomega.Expect(filepath.Join(dir, "b.txt")).ToNot(gomega.BeAnExistingFile())
+		})
+
+		ginkgo.It("Should trash the document of a removed local file", func(ctx ginkgo.SpecContext) {
+			gomega.Expect(os.Remove(filepath.Join(dir, "a.txt"))).To(gomega.Succeed())
+
+			plan, err := syncer.Sync(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(kinds(plan)).To(gomega.Equal([]string{"remote trash a.txt"}))
+			gomega.Expect(remoteDocuments(ctx, root.InternalID)).To(gomega.ConsistOf(
+				gstruct.PointTo(gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+					"Name": gomega.Equal("b.txt"),
+				})),
+			))
+		})
+
+		ginkgo.Context("When a file changed on both sides", func() {
+			ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+				writeFile("b.txt", "local change")
+
+				for _, document := range remoteDocuments(ctx, root.InternalID) {
+					if document.Name == "b.txt" {
+						gomega.Expect(client.Trash(ctx, []digiposte.DocumentID{document.InternalID}, nil)).To(gomega.Succeed())
+					}
+				}
+
+				server.AddDocument(root.InternalID, "b.txt", []byte("remote change"), digiposte.LocationSafe)
+			})
+
+			ginkgo.It("Should report the conflict and keep both sides", func(ctx ginkgo.SpecContext) {
+				plan, err := syncer.Sync(ctx)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+				gomega.Expect(kinds(plan)).To(gomega.Equal([]string{"local conflict b.txt"}))
+				gomega.Expect(readFile("b.txt")).To(gomega.Equal("local change"))
+
+				// The conflict is reported again until resolved.
+				plan, err = syncer.Plan(ctx)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+				gomega.Expect(kinds(plan)).To(gomega.Equal([]string{"local conflict b.txt"}))
+			})
+
+			ginkgo.It("Should upload the local file when it wins", func(ctx ginkgo.SpecContext) {
+				syncer = sync.New(client, dir, root.InternalID, sync.WithConflictPolicy(sync.ConflictLocalWins))
+
+				plan, err := syncer.Sync(ctx)
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+				gomega.Expect(kinds(plan)).To(gomega.Equal([]string{"remote upload b.txt"}))
+
+				documents := remoteDocuments(ctx, root.InternalID)
+				gomega.Expect(documents).To(gomega.HaveLen(2))
+
+				for _, document := range documents {
+					if document.Name == "b.txt" {
+						gomega.Expect(remoteContent(ctx, document)).To(gomega.Equal("local change"))
+					}
+				}
+			})
+		})
+	})
+})
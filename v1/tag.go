@@ -0,0 +1,185 @@
+package digiposte
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// MultiUntag removes the given tags from the given documents.
+func (c *Client) MultiUntag(ctx context.Context, tags map[DocumentID][]DocumentTag) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"tags": tags,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal body: %w", err)
+	}
+
+	req, err := c.apiRequest(ctx, http.MethodPost, "/v3/documents/multiUntag", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+
+	return c.call(req, nil, http.StatusOK)
+}
+
+// TagSummary is the result of an operation on a tag over all the documents.
+type TagSummary struct {
+	// DocumentsChanged is the number of documents whose tags changed.
+	DocumentsChanged int
+	// DocumentIDs are the documents whose tags changed.
+	DocumentIDs []DocumentID
+}
+
+// RenameTag replaces the tag oldTag by newTag on all the documents, including the trashed ones.
+func (c *Client) RenameTag(ctx context.Context, oldTag, newTag DocumentTag) (*TagSummary, error) {
+	return c.MergeTags(ctx, newTag, oldTag)
+}
+
+// MergeTags replaces the source tags by the target tag on all the documents, including the trashed ones.
+// The target tag is added first, so that a failure never loses the tags of a document.
+func (c *Client) MergeTags(ctx context.Context, target DocumentTag, sources ...DocumentTag) (*TagSummary, error) {
+	sources = withoutTag(sources, target)
+
+	documents, err := c.taggedDocuments(ctx, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	added := make(map[DocumentID][]DocumentTag)
+	removed := make(map[DocumentID][]DocumentTag)
+
+	for _, document := range documents {
+		if !hasTag(document, target) {
+			added[document.InternalID] = []DocumentTag{target}
+		}
+
+		removed[document.InternalID] = documentTags(document, sources)
+	}
+
+	if len(added) > 0 {
+		if err := c.MultiTag(ctx, added); err != nil {
+			return nil, fmt.Errorf("tag documents: %w", err)
+		}
+	}
+
+	if len(removed) > 0 {
+		if err := c.MultiUntag(ctx, removed); err != nil {
+			return nil, fmt.Errorf("untag documents: %w", err)
+		}
+	}
+
+	return newTagSummary(documents), nil
+}
+
+// DeleteTag removes the tag from all the documents, including the trashed ones.
+func (c *Client) DeleteTag(ctx context.Context, tag DocumentTag) (*TagSummary, error) {
+	documents, err := c.taggedDocuments(ctx, []DocumentTag{tag})
+	if err != nil {
+		return nil, err
+	}
+
+	removed := make(map[DocumentID][]DocumentTag, len(documents))
+
+	for _, document := range documents {
+		removed[document.InternalID] = []DocumentTag{tag}
+	}
+
+	if len(removed) > 0 {
+		if err := c.MultiUntag(ctx, removed); err != nil {
+			return nil, fmt.Errorf("untag documents: %w", err)
+		}
+	}
+
+	return newTagSummary(documents), nil
+}
+
+// inAllFolders searches the documents of all the folders, instead of a single one.
+func inAllFolders() DocumentSearchOption {
+	return func(body map[string]interface{}) {
+		delete(body, folderIDParam)
+	}
+}
+
+// taggedDocuments returns the documents having at least one of the tags, sorted by ID.
+// The trashed documents are included, so that they keep consistent tags once restored.
+func (c *Client) taggedDocuments(ctx context.Context, tags []DocumentTag) ([]*Document, error) {
+	found := make(map[DocumentID]*Document)
+
+	for _, tag := range tags {
+		documents, err := c.SearchDocumentsIter(ctx, RootFolderID,
+			DocumentTaggedWith(tag),
+			inAllFolders(),
+			OnlyDocumentLocatedAt(LocationInbox, LocationSafe, LocationTrashInbox, LocationTrashSafe),
+		).All()
+		if err != nil {
+			return nil, fmt.Errorf("search documents tagged with %q: %w", tag, err)
+		}
+
+		for _, document := range documents {
+			found[document.InternalID] = document
+		}
+	}
+
+	documents := make([]*Document, 0, len(found))
+	for _, document := range found {
+		documents = append(documents, document)
+	}
+
+	sort.Slice(documents, func(i, j int) bool {
+		return documents[i].InternalID < documents[j].InternalID
+	})
+
+	return documents, nil
+}
+
+func newTagSummary(documents []*Document) *TagSummary {
+	summary := &TagSummary{
+		DocumentsChanged: len(documents),
+		DocumentIDs:      make([]DocumentID, 0, len(documents)),
+	}
+
+	for _, document := range documents {
+		summary.DocumentIDs = append(summary.DocumentIDs, document.InternalID)
+	}
+
+	return summary
+}
+
+func hasTag(document *Document, tag DocumentTag) bool {
+	for _, current := range document.UserTags {
+		if DocumentTag(current) == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// documentTags returns the tags the document has among the given ones.
+func documentTags(document *Document, tags []DocumentTag) []DocumentTag {
+	var result []DocumentTag
+
+	for _, tag := range tags {
+		if hasTag(document, tag) {
+			result = append(result, tag)
+		}
+	}
+
+	return result
+}
+
+func withoutTag(tags []DocumentTag, excluded DocumentTag) []DocumentTag {
+	result := make([]DocumentTag, 0, len(tags))
+
+	for _, tag := range tags {
+		if tag != excluded {
+			result = append(result, tag)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,100 @@
+package digiposte_test
+
+import (
+	"net/http"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+	"github.com/holyhope/digiposte-go-sdk/v1/digipostetest"
+)
+
+// The tag operations change all the documents of the account, so they run against the fake server.
+var _ = ginkgo.Describe("Tags", func() {
+	var (
+		client    *digiposte.Client
+		avis      *digiposte.Document
+		facture   *digiposte.Document
+		untouched *digiposte.Document
+	)
+
+	ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+		server := digipostetest.NewServer()
+		ginkgo.DeferCleanup(server.Close)
+
+		var err error
+
+		client, err = digiposte.NewAuthenticatedClient(ctx, new(http.Client), server.Config())
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		folder := server.AddFolder(digiposte.RootFolderID, "Impôts")
+
+		avis = server.AddDocument(folder.InternalID, "avis.pdf", []byte("avis"), digiposte.LocationSafe)
+		facture = server.AddDocument(digiposte.RootFolderID, "facture.pdf", []byte("facture"), digiposte.LocationSafe)
+		untouched = server.AddDocument(digiposte.RootFolderID, "other.pdf", []byte("other"), digiposte.LocationSafe)
+
+		gomega.Expect(client.MultiTag(ctx, map[digiposte.DocumentID][]digiposte.DocumentTag{
+			avis.InternalID:      {"taxes", "2024"},
+			facture.InternalID:   {"impots"},
+			untouched.InternalID: {"bank"},
+		})).To(gomega.Succeed())
+	})
+
+	userTags := func(ctx ginkgo.SpecContext) map[digiposte.DocumentTag]int {
+		result, err := client.UserTags(ctx)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		return result.Tags
+	}
+
+	ginkgo.It("Should remove tags from documents", func(ctx ginkgo.SpecContext) {
+		gomega.Expect(client.MultiUntag(ctx, map[digiposte.DocumentID][]digiposte.DocumentTag{
+			avis.InternalID: {"2024"},
+		})).To(gomega.Succeed())
+
+		gomega.Expect(userTags(ctx)).To(gomega.Equal(map[digiposte.DocumentTag]int{"taxes": 1, "impots": 1, "bank": 1}))
+	})
+
+	ginkgo.It("Should rename a tag in all the folders", func(ctx ginkgo.SpecContext) {
+		summary, err := client.RenameTag(ctx, "taxes", "impots")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(summary.DocumentsChanged).To(gomega.Equal(1))
+		gomega.Expect(summary.DocumentIDs).To(gomega.ConsistOf(avis.InternalID))
+
+		gomega.Expect(userTags(ctx)).To(gomega.Equal(map[digiposte.DocumentTag]int{"impots": 2, "2024": 1, "bank": 1}))
+	})
+
+	ginkgo.It("Should rename the tag of the trashed documents", func(ctx ginkgo.SpecContext) {
+		gomega.Expect(client.Trash(ctx, []digiposte.DocumentID{avis.InternalID}, nil)).To(gomega.Succeed())
+
+		summary, err := client.RenameTag(ctx, "taxes", "impots")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(summary.DocumentIDs).To(gomega.ConsistOf(avis.InternalID))
+
+		trashed, err := client.GetTrashedDocuments(ctx)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(trashed.Documents).To(gomega.HaveLen(1))
+		gomega.Expect(trashed.Documents[0].UserTags).To(gomega.ConsistOf("impots", "2024"))
+	})
+
+	ginkgo.It("Should merge tags", func(ctx ginkgo.SpecContext) {
+		summary, err := client.MergeTags(ctx, "taxes", "impots", "2024", "taxes")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(summary.DocumentIDs).To(gomega.ConsistOf(avis.InternalID, facture.InternalID))
+
+		gomega.Expect(userTags(ctx)).To(gomega.Equal(map[digiposte.DocumentTag]int{"taxes": 2, "bank": 1}))
+	})
+
+	ginkgo.It("Should delete a tag everywhere", func(ctx ginkgo.SpecContext) {
+		summary, err := client.DeleteTag(ctx, "bank")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(summary.DocumentsChanged).To(gomega.Equal(1))
+
+		summary, err = client.DeleteTag(ctx, "missing")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(summary.DocumentsChanged).To(gomega.BeZero())
+
+		gomega.Expect(userTags(ctx)).ToNot(gomega.HaveKey(digiposte.DocumentTag("bank")))
+	})
+})
@@ -0,0 +1,151 @@
+package digiposte
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RestoreFailure is a document or a folder that could not be restored.
+type RestoreFailure struct {
+	// DocumentID is set when the item is a document.
+	DocumentID DocumentID
+	// FolderID is set when the item is a folder.
+	FolderID FolderID
+	Err      error
+}
+
+func (f *RestoreFailure) Error() string {
+	if f.DocumentID != "" {
+		return fmt.Sprintf("document %s: %v", f.DocumentID, f.Err)
+	}
+
+	return fmt.Sprintf("folder %s: %v", f.FolderID, f.Err)
+}
+
+func (f *RestoreFailure) Unwrap() error {
+	return f.Err
+}
+
+// RestoreError is returned by Restore when some documents or folders could not be restored.
+// The other items are restored.
+type RestoreError struct {
+	Failures []*RestoreFailure
+}
+
+func (e *RestoreError) Error() string {
+	strs := make([]string, 0, len(e.Failures))
+
+	for _, failure := range e.Failures {
+		strs = append(strs, failure.Error())
+	}
+
+	return fmt.Sprintf("%d items not restored: %s", len(e.Failures), strings.Join(strs, "; "))
+}
+
+func (e *RestoreError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+
+	for _, failure := range e.Failures {
+		errs = append(errs, failure)
+	}
+
+	return errs
+}
+
+// Restore moves the given documents and folders out of the trash, back to their folder.
+//
+// When some items cannot be restored, for example because their folder no longer exists,
+// the others are restored and a *RestoreError lists the failures.
+func (c *Client) Restore(ctx context.Context, documentIDs []DocumentID, folderIDs []FolderID) error {
+	err := c.untrash(ctx, documentIDs, folderIDs)
+	if err == nil || !isItemFailure(err) {
+		return err
+	}
+
+	if len(documentIDs)+len(folderIDs) == 1 {
+		return &RestoreError{Failures: []*RestoreFailure{restoreFailure(documentIDs, folderIDs, err)}}
+	}
+
+	// The whole request is rejected: restore the items one by one to find the failing ones.
+	// The folders go first, so that the documents they contain find their folder again.
+	var failures []*RestoreFailure
+
+	for _, folderID := range folderIDs {
+		if err := c.untrash(ctx, nil, []FolderID{folderID}); err != nil {
+			if !isItemFailure(err) {
+				return err
+			}
+
+			failures = append(failures, restoreFailure(nil, []FolderID{folderID}, err))
+		}
+	}
+
+	for _, documentID := range documentIDs {
+		if err := c.untrash(ctx, []DocumentID{documentID}, nil); err != nil {
+			if !isItemFailure(err) {
+				return err
+			}
+
+			failures = append(failures, restoreFailure([]DocumentID{documentID}, nil, err))
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	return &RestoreError{Failures: failures}
+}
+
+func restoreFailure(documentIDs []DocumentID, folderIDs []FolderID, err error) *RestoreFailure {
+	failure := &RestoreFailure{DocumentID: "", FolderID: "", Err: err}
+
+	if len(documentIDs) > 0 {
+		failure.DocumentID = documentIDs[0]
+	} else {
+		failure.FolderID = folderIDs[0]
+	}
+
+	return failure
+}
+
+// isItemFailure reports whether the error is caused by the items of the request,
+// rather than by the session or the server.
+func isItemFailure(err error) bool {
+	return errors.Is(err, ErrNotFound) ||
+		errors.Is(err, ErrConflict) ||
+		errors.Is(err, ErrForbidden) ||
+		errors.Is(err, ErrValidation)
+}
+
+// EmptyTrash deletes permanently all the documents and folders in the trash,
+// from both LocationTrashInbox and LocationTrashSafe.
+func (c *Client) EmptyTrash(ctx context.Context) error {
+	documents, err := c.TrashedDocumentsIter(ctx, OnlyDocumentLocatedAt(LocationTrashInbox, LocationTrashSafe)).All()
+	if err != nil {
+		return fmt.Errorf("get trashed documents: %w", err)
+	}
+
+	folders, err := c.GetTrashedFolders(ctx)
+	if err != nil {
+		return fmt.Errorf("get trashed folders: %w", err)
+	}
+
+	documentIDs := make([]DocumentID, 0, len(documents))
+	for _, document := range documents {
+		documentIDs = append(documentIDs, document.InternalID)
+	}
+
+	folderIDs := make([]FolderID, 0, len(folders.Folders))
+	for _, folder := range folders.Folders {
+		folderIDs = append(folderIDs, folder.InternalID)
+	}
+
+	if len(documentIDs) == 0 && len(folderIDs) == 0 {
+		return nil
+	}
+
+	return c.Delete(ctx, documentIDs, folderIDs)
+}
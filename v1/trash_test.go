@@ -0,0 +1,92 @@
+package digiposte_test
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+	"github.com/holyhope/digiposte-go-sdk/v1/digipostetest"
+)
+
+var _ = ginkgo.Describe("Trash", func() {
+	var (
+		server *digipostetest.Server
+		client *digiposte.Client
+		folder *digiposte.Folder
+		inner  *digiposte.Document
+		outer  *digiposte.Document
+	)
+
+	ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+		server = digipostetest.NewServer()
+		ginkgo.DeferCleanup(server.Close)
+
+		var err error
+
+		client, err = digiposte.NewAuthenticatedClient(ctx, new(http.Client), server.Config())
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		folder = server.AddFolder(digiposte.RootFolderID, "Impôts")
+		inner = server.AddDocument(folder.InternalID, "avis.pdf", []byte("inner"), digiposte.LocationSafe)
+		outer = server.AddDocument(digiposte.RootFolderID, "facture.pdf", []byte("outer"), digiposte.LocationSafe)
+	})
+
+	trashedIDs := func(ctx ginkgo.SpecContext) []digiposte.DocumentID {
+		documents, err := client.TrashedDocumentsIter(ctx).All()
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		ids := make([]digiposte.DocumentID, 0, len(documents))
+		for _, document := range documents {
+			ids = append(ids, document.InternalID)
+		}
+
+		return ids
+	}
+
+	ginkgo.It("Should restore documents and folders", func(ctx ginkgo.SpecContext) {
+		documentIDs := []digiposte.DocumentID{outer.InternalID}
+		folderIDs := []digiposte.FolderID{folder.InternalID}
+
+		gomega.Expect(client.Trash(ctx, documentIDs, folderIDs)).To(gomega.Succeed())
+		gomega.Expect(trashedIDs(ctx)).To(gomega.ConsistOf(inner.InternalID, outer.InternalID))
+
+		gomega.Expect(client.Restore(ctx, documentIDs, folderIDs)).To(gomega.Succeed())
+		gomega.Expect(trashedIDs(ctx)).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should report the items whose folder no longer exists", func(ctx ginkgo.SpecContext) {
+		gomega.Expect(client.Trash(ctx, []digiposte.DocumentID{inner.InternalID, outer.InternalID}, nil)).To(gomega.Succeed())
+		gomega.Expect(client.Trash(ctx, nil, []digiposte.FolderID{folder.InternalID})).To(gomega.Succeed())
+
+		err := client.Restore(ctx, []digiposte.DocumentID{inner.InternalID, outer.InternalID}, nil)
+		gomega.Expect(err).To(gomega.MatchError(digiposte.ErrNotFound))
+
+		var restoreErr *digiposte.RestoreError
+
+		gomega.Expect(errors.As(err, &restoreErr)).To(gomega.BeTrue())
+		gomega.Expect(restoreErr.Failures).To(gomega.HaveLen(1))
+		gomega.Expect(restoreErr.Failures[0].DocumentID).To(gomega.Equal(inner.InternalID))
+
+		gomega.Expect(trashedIDs(ctx)).To(gomega.ConsistOf(inner.InternalID))
+	})
+
+	ginkgo.It("Should empty the trash", func(ctx ginkgo.SpecContext) {
+		gomega.Expect(client.EmptyTrash(ctx)).To(gomega.Succeed())
+
+		gomega.Expect(client.Trash(ctx, []digiposte.DocumentID{outer.InternalID}, []digiposte.FolderID{folder.InternalID})).
+			To(gomega.Succeed())
+		gomega.Expect(client.EmptyTrash(ctx)).To(gomega.Succeed())
+
+		gomega.Expect(trashedIDs(ctx)).To(gomega.BeEmpty())
+
+		folders, err := client.GetTrashedFolders(ctx)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(folders.Folders).To(gomega.BeEmpty())
+
+		gomega.Expect(client.Restore(ctx, []digiposte.DocumentID{outer.InternalID}, nil)).
+			To(gomega.MatchError(digiposte.ErrNotFound))
+	})
+})
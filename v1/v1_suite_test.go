@@ -11,12 +11,13 @@ import (
 
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
-	"golang.org/x/time/rate"
 
 	"github.com/holyhope/digiposte-go-sdk/internal/utils"
 	digipoauth "github.com/holyhope/digiposte-go-sdk/login"
 	"github.com/holyhope/digiposte-go-sdk/login/chrome"
+	"github.com/holyhope/digiposte-go-sdk/settings"
 	"github.com/holyhope/digiposte-go-sdk/v1"
+	"github.com/holyhope/digiposte-go-sdk/v1/digipostetest"
 )
 
 func DigiposteClient(ctx context.Context) (*digiposte.Client, error) {
@@ -38,13 +39,25 @@ func DigiposteClient(ctx context.Context) (*digiposte.Client, error) {
 }
 
 func newDigiposteClient(ctx context.Context) (*digiposte.Client, error) {
+	if os.Getenv("DIGIPOSTE_USERNAME") == "" {
+		return newFakeDigiposteClient(ctx)
+	}
+
+	apiURL, documentURL = os.Getenv("DIGIPOSTE_API"), os.Getenv("DIGIPOSTE_URL")
+
+	if apiURL == "" {
+		apiURL = settings.DefaultAPIURL
+	}
+
+	if documentURL == "" {
+		documentURL = settings.DefaultDocumentURL
+	}
+
 	path, err := utils.GetChrome(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("get chrome: %w", err)
 	}
 
-	documentURL := os.Getenv("DIGIPOSTE_URL") // or use digiposte.DefaultDocumentURL
-
 	chromeMethod, err := chrome.New(
 		chrome.WithURL(documentURL),
 		chrome.WithRefreshFrequency(500*time.Millisecond), // Reduce the test duration
@@ -56,15 +69,8 @@ func newDigiposteClient(ctx context.Context) (*digiposte.Client, error) {
 		return nil, fmt.Errorf("new chrome: %w", err)
 	}
 
-	// Rate limit the requests to avoid being blocked
-	rateLimitedClient := *http.DefaultClient
-	rateLimitedClient.Transport = &rateLimitedTransport{
-		RoundTripper: http.DefaultTransport,
-		rateLimiter:  rate.NewLimiter(rate.Every(1*time.Second), 5),
-	}
-
-	client, err := digiposte.NewAuthenticatedClient(ctx, &rateLimitedClient, &digiposte.Config{
-		APIURL:      os.Getenv("DIGIPOSTE_API"),
+	client, err := digiposte.NewAuthenticatedClient(ctx, new(http.Client), &digiposte.Config{
+		APIURL:      apiURL,
 		DocumentURL: documentURL,
 		LoginMethod: chromeMethod,
 		Credentials: &digipoauth.Credentials{
@@ -72,8 +78,14 @@ func newDigiposteClient(ctx context.Context) (*digiposte.Client, error) {
 			Password:  os.Getenv("DIGIPOSTE_PASSWORD"),
 			OTPSecret: os.Getenv("DIGIPOSTE_OTP_SECRET"),
 		},
-		SessionListener: nil,
-		PreviousSession: nil,
+		SessionListener:  nil,
+		PreviousSession:  nil,
+		SessionStore:     nil,
+		SessionSaveError: nil,
+		// Rate limit the requests to avoid being blocked
+		ClientOptions: []digiposte.ClientOption{
+			digiposte.WithRateLimit(digiposte.DefaultRateLimit(), digiposte.DefaultRateLimit()),
+		},
 	})
 	if err != nil {
 		screenshot, ok := chrome.GetScreenShot(err)
@@ -91,28 +103,28 @@ func newDigiposteClient(ctx context.Context) (*digiposte.Client, error) {
 	return client, nil
 }
 
-type rateLimitedTransport struct {
-	http.RoundTripper
-	rateLimiter *rate.Limiter
-}
+// newFakeDigiposteClient returns a client connected to an in-memory Digiposte server.
+// The server is never closed: examples reuse the client after the suite ends.
+func newFakeDigiposteClient(ctx context.Context) (*digiposte.Client, error) {
+	server := digipostetest.NewServer()
 
-func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	if err := t.rateLimiter.Wait(req.Context()); err != nil {
-		return nil, fmt.Errorf("rate limited: %w", err)
-	}
+	apiURL, documentURL = server.APIURL(), server.DocumentURL()
 
-	resp, err := t.RoundTripper.RoundTrip(req)
+	client, err := digiposte.NewAuthenticatedClient(ctx, new(http.Client), server.Config())
 	if err != nil {
-		return nil, fmt.Errorf("round trip: %w", err)
+		return nil, fmt.Errorf("new client: %w", err)
 	}
 
-	return resp, nil
+	return client, nil
 }
 
 //nolint:gochecknoglobals
 var (
 	digiposteClient     *digiposte.Client
 	digiposteClientLock sync.Mutex
+
+	// apiURL and documentURL are the URLs the digiposteClient is connected to.
+	apiURL, documentURL string
 )
 
 var _ = ginkgo.BeforeSuite(func(ctx ginkgo.SpecContext) {
@@ -0,0 +1,306 @@
+package digiposte
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// walkConcurrency is the maximum number of folders whose documents are searched at the same time.
+const walkConcurrency = 4
+
+// WalkFunc is called by Walk for each folder and document, as fs.WalkDirFunc is by fs.WalkDir.
+//
+// The path is relative to the walked folder, which is ".", and its names are escaped with SanitizeName,
+// so it can be resolved by a Resolver. If the documents of a folder cannot be
+// searched, the function is called a second time for the folder, with the error.
+// Returning fs.SkipDir skips the folder, or the rest of the parent folder when returned for a document.
+// Returning fs.SkipAll stops the walk. Any other error stops the walk and is returned by Walk.
+type WalkFunc func(path string, entry *PathEntry, err error) error
+
+// Walk visits the folder, its documents and its sub-folders, recursively and depth-first.
+// The entries of each folder are visited by name. Use RootFolderID to walk the whole safe.
+//
+// The folder tree is loaded once, then the documents of the next folders are searched concurrently.
+func (c *Client) Walk(ctx context.Context, rootID FolderID, walkFn WalkFunc) error {
+	tree, err := c.folderTree(ctx)
+	if err != nil {
+		return skipToNil(walkFn(".", nil, err))
+	}
+
+	root, ok := findFolder(tree, rootID)
+	if !ok {
+		return skipToNil(walkFn(".", nil, &PathError{Path: string(rootID), Segment: "", Err: ErrNotFound}))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	walker := newWalker(c, root)
+	walker.start(ctx)
+
+	defer func() {
+		cancel()
+		walker.wait()
+	}()
+
+	return skipToNil(walker.walk(".", root, walkFn))
+}
+
+func skipToNil(err error) error {
+	if errors.Is(err, fs.SkipDir) || errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+
+	return err
+}
+
+// findFolder returns the folder with the given ID in the tree.
+func findFolder(parent *Folder, folderID FolderID) (*Folder, bool) {
+	if parent.InternalID == folderID {
+		return parent, true
+	}
+
+	for _, folder := range parent.Folders {
+		if found, ok := findFolder(folder, folderID); ok {
+			return found, true
+		}
+	}
+
+	return nil, false
+}
+
+// folderListing is the result of the search of the documents of a folder.
+type folderListing struct {
+	folderID FolderID
+	skipped  atomic.Bool
+
+	done      chan struct{}
+	documents []*Document
+	err       error
+}
+
+// walker searches the documents of the folders, in the order they are visited.
+type walker struct {
+	client   *Client
+	listings map[FolderID]*folderListing
+	order    []*folderListing
+	group    sync.WaitGroup
+}
+
+func newWalker(client *Client, root *Folder) *walker {
+	walker := &walker{
+		client:   client,
+		listings: make(map[FolderID]*folderListing),
+		order:    nil,
+		group:    sync.WaitGroup{},
+	}
+
+	walker.add(root)
+
+	return walker
+}
+
+func (w *walker) add(folder *Folder) {
+	listing := &folderListing{
+		folderID:  folder.InternalID,
+		skipped:   atomic.Bool{},
+		done:      make(chan struct{}),
+		documents: nil,
+		err:       nil,
+	}
+
+	w.listings[folder.InternalID] = listing
+	w.order = append(w.order, listing)
+
+	for _, sub := range sortedFolders(folder) {
+		w.add(sub)
+	}
+}
+
+// start searches the documents of the folders in the background, a few at a time.
+func (w *walker) start(ctx context.Context) {
+	w.group.Add(1)
+
+	go func() {
+		defer w.group.Done()
+
+		tokens := make(chan struct{}, walkConcurrency)
+
+		for _, listing := range w.order {
+			if listing.skipped.Load() {
+				close(listing.done)
+
+				continue
+			}
+
+			select {
+			case tokens <- struct{}{}:
+			case <-ctx.Done():
+				listing.err = ctx.Err()
+				close(listing.done)
+
+				continue
+			}
+
+			w.group.Add(1)
+
+			go func(listing *folderListing) {
+				defer w.group.Done()
+				defer func() { <-tokens }()
+
+				listing.documents, listing.err = w.client.SearchDocumentsIter(ctx, listing.folderID).All()
+				close(listing.done)
+			}(listing)
+		}
+	}()
+}
+
+func (w *walker) wait() {
+	w.group.Wait()
+}
+
+// skip avoids searching the documents of the folder and its sub-folders.
+func (w *walker) skip(folder *Folder) {
+	w.listings[folder.InternalID].skipped.Store(true)
+
+	for _, sub := range folder.Folders {
+		w.skip(sub)
+	}
+}
+
+func (w *walker) walk(name string, folder *Folder, walkFn WalkFunc) error {
+	entry := &PathEntry{Path: name, Folder: folder, Document: nil}
+
+	if err := walkFn(name, entry, nil); err != nil {
+		if errors.Is(err, fs.SkipDir) {
+			w.skip(folder)
+
+			return nil
+		}
+
+		return err
+	}
+
+	listing := w.listings[folder.InternalID]
+	<-listing.done
+
+	if listing.err != nil {
+		if err := walkFn(name, entry, fmt.Errorf("search documents: %w", listing.err)); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				w.skip(folder)
+
+				return nil
+			}
+
+			return err
+		}
+	}
+
+	children := folderEntries(folder, listing.documents)
+
+	for index, child := range children {
+		childPath := path.Join(name, SanitizeName(child.name))
+
+		var err error
+
+		if child.folder != nil {
+			err = w.walk(childPath, child.folder, walkFn)
+		} else {
+			err = walkFn(childPath, &PathEntry{Path: childPath, Folder: nil, Document: child.document}, nil)
+		}
+
+		if err == nil {
+			continue
+		}
+
+		if errors.Is(err, fs.SkipDir) {
+			for _, next := range children[index+1:] {
+				if next.folder != nil {
+					w.skip(next.folder)
+				}
+			}
+
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+type folderEntry struct {
+	name     string
+	folder   *Folder
+	document *Document
+}
+
+// folderEntries returns the sub-folders and the documents, sorted by name.
+func folderEntries(folder *Folder, documents []*Document) []*folderEntry {
+	entries := make([]*folderEntry, 0, len(folder.Folders)+len(documents))
+
+	for _, sub := range sortedFolders(folder) {
+		entries = append(entries, &folderEntry{name: sub.Name, folder: sub, document: nil})
+	}
+
+	for _, document := range documents {
+		entries = append(entries, &folderEntry{name: document.Name, folder: nil, document: document})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+
+	return entries
+}
+
+func sortedFolders(folder *Folder) []*Folder {
+	folders := append([]*Folder(nil), folder.Folders...)
+
+	sort.SliceStable(folders, func(i, j int) bool {
+		return folders[i].Name < folders[j].Name
+	})
+
+	return folders
+}
+
+// MkdirAll returns the folder at the given slash-separated path under the parent folder,
+// creating the missing folders. Use RootFolderID to start from the root of the safe.
+func (c *Client) MkdirAll(ctx context.Context, parentID FolderID, name string) (*Folder, error) {
+	tree, err := c.folderTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, ok := findFolder(tree, parentID)
+	if !ok {
+		return nil, &PathError{Path: string(parentID), Segment: "", Err: ErrNotFound}
+	}
+
+	cleaned, segments := cleanPath(name)
+
+	for _, segment := range segments {
+		child, err := childFolder(parent, segment)
+
+		switch {
+		case err == nil:
+			parent = child
+
+			continue
+		case !errors.Is(err, ErrNotFound):
+			return nil, &PathError{Path: cleaned, Segment: segment, Err: err}
+		}
+
+		parent, err = c.CreateFolder(ctx, parent.InternalID, segment)
+		if err != nil {
+			return nil, fmt.Errorf("create folder %q: %w", segment, err)
+		}
+	}
+
+	return parent, nil
+}
@@ -0,0 +1,118 @@
+package digiposte_test
+
+import (
+	"io/fs"
+	"strings"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+var _ = ginkgo.Describe("Walk", func() {
+	var (
+		folder    *digiposte.Folder
+		documents []digiposte.DocumentID
+	)
+
+	ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+		var err error
+
+		folder, err = digiposteClient.CreateFolder(ctx, digiposte.RootFolderID, "walk test "+ginkgo.CurrentSpecReport().LeafNodeText)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		documents = nil
+
+		first, err := digiposteClient.MkdirAll(ctx, folder.InternalID, "a/b")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		second, err := digiposteClient.MkdirAll(ctx, folder.InternalID, "c")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		for _, item := range []struct {
+			folderID digiposte.FolderID
+			name     string
+		}{
+			{folder.InternalID, "z.txt"},
+			{first.InternalID, "b1.txt"},
+			{first.InternalID, "b2.txt"},
+			{second.InternalID, "c/1.txt"},
+		} {
+			document, err := digiposteClient.CreateDocument(ctx,
+				item.folderID,
+				item.name,
+				strings.NewReader("content"),
+				digiposte.DocumentTypeBasic,
+			)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			documents = append(documents, document.InternalID)
+		}
+	})
+
+	ginkgo.AfterEach(func(ctx ginkgo.SpecContext) {
+		folders := []digiposte.FolderID{folder.InternalID}
+
+		gomega.Expect(digiposteClient.Trash(ctx, documents, folders)).To(gomega.Succeed())
+		gomega.Expect(digiposteClient.Delete(ctx, documents, folders)).To(gomega.Succeed())
+	})
+
+	walk := func(ctx ginkgo.SpecContext, walkFn func(path string, entry *digiposte.PathEntry) error) []string {
+		var paths []string
+
+		gomega.Expect(digiposteClient.Walk(ctx, folder.InternalID, func(path string, entry *digiposte.PathEntry, err error) error {
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(entry.Path).To(gomega.Equal(path))
+
+			paths = append(paths, path)
+
+			return walkFn(path, entry)
+		})).To(gomega.Succeed())
+
+		return paths
+	}
+
+	ginkgo.It("Should visit every folder and document depth-first", func(ctx ginkgo.SpecContext) {
+		gomega.Expect(walk(ctx, func(string, *digiposte.PathEntry) error { return nil })).To(gomega.Equal([]string{
+			".", "a", "a/b", "a/b/b1.txt", "a/b/b2.txt", "c", "c/c_1.txt", "z.txt",
+		}))
+	})
+
+	ginkgo.It("Should skip folders", func(ctx ginkgo.SpecContext) {
+		gomega.Expect(walk(ctx, func(path string, _ *digiposte.PathEntry) error {
+			if path == "a" || path == "c/c_1.txt" {
+				return fs.SkipDir
+			}
+
+			return nil
+		})).To(gomega.Equal([]string{".", "a", "c", "c/c_1.txt", "z.txt"}))
+	})
+
+	ginkgo.It("Should stop the walk", func(ctx ginkgo.SpecContext) {
+		gomega.Expect(walk(ctx, func(path string, _ *digiposte.PathEntry) error {
+			if path == "a/b/b1.txt" {
+				return fs.SkipAll
+			}
+
+			return nil
+		})).To(gomega.Equal([]string{".", "a", "a/b", "a/b/b1.txt"}))
+	})
+
+	ginkgo.It("Should reuse the existing folders", func(ctx ginkgo.SpecContext) {
+		existing, err := digiposteClient.NewResolver().ResolveFolder(ctx, folder.Name+"/a/b")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		created, err := digiposteClient.MkdirAll(ctx, folder.InternalID, "/a/b/")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(created.InternalID).To(gomega.Equal(existing.InternalID))
+
+		created, err = digiposteClient.MkdirAll(ctx, folder.InternalID, "a/b/d/e")
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(created.Name).To(gomega.Equal("e"))
+
+		path, err := digiposteClient.NewResolver().FolderPath(ctx, created.InternalID)
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(path).To(gomega.Equal(folder.Name + "/a/b/d/e"))
+	})
+})
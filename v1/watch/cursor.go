@@ -0,0 +1,99 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/holyhope/digiposte-go-sdk/internal/utils"
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+// cursorVersion is the version of the format of the cursor file.
+const cursorVersion = 1
+
+var errCursorVersion = errors.New("unsupported cursor version")
+
+// Cursor is the content of the cursor file: the unread documents of the inbox seen by the last poll.
+type Cursor struct {
+	Version   int                                    `json:"version"`
+	PolledAt  time.Time                              `json:"polled_at"`
+	Documents map[digiposte.DocumentID]DocumentState `json:"documents"`
+}
+
+// DocumentState is a document seen by a poll.
+type DocumentState struct {
+	Name     string   `json:"name"`
+	FolderID string   `json:"folder_id"`
+	Tags     []string `json:"tags"`
+}
+
+func newCursor() *Cursor {
+	return &Cursor{
+		Version:   cursorVersion,
+		PolledAt:  time.Time{},
+		Documents: make(map[digiposte.DocumentID]DocumentState),
+	}
+}
+
+func stateOf(document *digiposte.Document) DocumentState {
+	return DocumentState{
+		Name:     document.Name,
+		FolderID: document.FolderID,
+		Tags:     append([]string{}, document.UserTags...),
+	}
+}
+
+// loadCursor reads the cursor file, and reports whether it was found.
+// Without a cursor file, or if it is missing, the cursor is empty.
+func (w *Watcher) loadCursor() (*Cursor, bool, error) {
+	if w.cursorFile == "" {
+		return newCursor(), false, nil
+	}
+
+	content, err := os.ReadFile(w.cursorFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return newCursor(), false, nil
+	}
+
+	if err != nil {
+		return nil, false, fmt.Errorf("read cursor: %w", err)
+	}
+
+	cursor := newCursor()
+	if err := json.Unmarshal(content, cursor); err != nil {
+		return nil, false, fmt.Errorf("decode cursor %q: %w", w.cursorFile, err)
+	}
+
+	if cursor.Version != cursorVersion {
+		return nil, false, fmt.Errorf("%w: %d", errCursorVersion, cursor.Version)
+	}
+
+	if cursor.Documents == nil {
+		cursor.Documents = make(map[digiposte.DocumentID]DocumentState)
+	}
+
+	return cursor, true, nil
+}
+
+// saveCursor writes the cursor file atomically, if any.
+func (w *Watcher) saveCursor(cursor *Cursor) error {
+	if w.cursorFile == "" {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(cursor, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cursor: %w", err)
+	}
+
+	err = utils.WriteFile(w.cursorFile, ".digiposte-cursor-*", time.Time{}, utils.WriteContent(content))
+	if err != nil {
+		return fmt.Errorf("write cursor file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,315 @@
+// Package watch polls the Digiposte inbox and reports the changes of its unread documents as events.
+//
+// A Watcher searches the unread documents of the inbox on an interval, compares them with a cursor
+// holding the documents seen by the previous poll, and calls a handler for each difference.
+// The cursor can be saved to a file, so that a restarted watcher only reports what changed meanwhile.
+package watch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/holyhope/digiposte-go-sdk/internal/utils"
+	"github.com/holyhope/digiposte-go-sdk/v1"
+)
+
+// Default timings of a Watcher.
+const (
+	DefaultInterval   = 5 * time.Minute
+	DefaultMaxBackoff = time.Hour
+)
+
+// EventKind is the kind of an Event.
+type EventKind int
+
+const (
+	// DocumentAdded is a document new to the unread inbox.
+	DocumentAdded EventKind = iota
+	// DocumentRemoved is a document no longer in the unread inbox: read, moved to the safe, trashed or deleted.
+	DocumentRemoved
+	// DocumentMoved is a document renamed or moved to another folder, still in the unread inbox.
+	DocumentMoved
+	// TagsChanged is a document whose tags changed.
+	TagsChanged
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case DocumentAdded:
+		return "added"
+	case DocumentRemoved:
+		return "removed"
+	case DocumentMoved:
+		return "moved"
+	case TagsChanged:
+		return "tags changed"
+	default:
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+}
+
+// Event is a change of the unread documents of the inbox.
+// A document can have several events in the same poll, such as being moved and retagged.
+type Event struct {
+	Kind       EventKind
+	DocumentID digiposte.DocumentID
+	// Document is the document after the change, nil when removed.
+	Document *digiposte.Document
+	// Previous is the document as seen by the previous poll, nil when added.
+	Previous *DocumentState
+}
+
+// Watcher polls the unread documents of the inbox. It is not safe for concurrent use.
+type Watcher struct {
+	client         *digiposte.Client
+	interval       time.Duration
+	maxBackoff     time.Duration
+	cursorFile     string
+	ignoreExisting bool
+	onError        func(error)
+
+	cursor *Cursor
+}
+
+// Option configures a Watcher.
+type Option func(w *Watcher)
+
+// WithInterval sets the time between two polls, which must be positive. The default is DefaultInterval.
+func WithInterval(interval time.Duration) Option {
+	return func(w *Watcher) {
+		w.interval = interval
+	}
+}
+
+// WithMaxBackoff sets the longest time between two polls after errors, which must be positive.
+// The default is DefaultMaxBackoff.
+// The time between two polls doubles after each consecutive error, starting from the interval.
+func WithMaxBackoff(maxBackoff time.Duration) Option {
+	return func(w *Watcher) {
+		w.maxBackoff = maxBackoff
+	}
+}
+
+// WithCursorFile sets the file the cursor is saved to after each poll, and loaded from on the first poll.
+// By default, the cursor is only kept in memory.
+func WithCursorFile(path string) Option {
+	return func(w *Watcher) {
+		w.cursorFile = path
+	}
+}
+
+// IgnoreExisting does not report the documents already in the inbox on the first poll, without a saved cursor.
+func IgnoreExisting() Option {
+	return func(w *Watcher) {
+		w.ignoreExisting = true
+	}
+}
+
+// WithErrorHandler sets a function receiving the errors of the polls of Run, before backing off.
+func WithErrorHandler(onError func(error)) Option {
+	return func(w *Watcher) {
+		w.onError = onError
+	}
+}
+
+// New returns a Watcher of the unread documents of the inbox.
+func New(client *digiposte.Client, options ...Option) *Watcher {
+	watcher := &Watcher{
+		client:         client,
+		interval:       DefaultInterval,
+		maxBackoff:     DefaultMaxBackoff,
+		cursorFile:     "",
+		ignoreExisting: false,
+		onError:        func(error) {},
+		cursor:         nil,
+	}
+
+	for _, option := range options {
+		option(watcher)
+	}
+
+	return watcher
+}
+
+// Run polls until the context is done, and returns its error.
+// The errors of the polls are given to the error handler, and delay the next poll.
+// It fails with digiposte.ErrValidation if the interval or the maximum backoff is not positive.
+func (w *Watcher) Run(ctx context.Context, handle func(Event)) error {
+	if w.interval <= 0 || w.maxBackoff <= 0 {
+		return fmt.Errorf("%w: the interval (%s) and the maximum backoff (%s) must be positive",
+			digiposte.ErrValidation, w.interval, w.maxBackoff)
+	}
+
+	failures := 0
+
+	for {
+		delay := w.interval
+
+		if err := w.Poll(ctx, handle); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err() //nolint:wrapcheck
+			}
+
+			failures++
+
+			w.onError(err)
+
+			delay = w.backoff(failures)
+		} else {
+			failures = 0
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return ctx.Err() //nolint:wrapcheck
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the time to wait after the given number of consecutive failures.
+func (w *Watcher) backoff(failures int) time.Duration {
+	delay := w.interval
+
+	for i := 0; i < failures && delay < w.maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > w.maxBackoff {
+		return w.maxBackoff
+	}
+
+	return delay
+}
+
+// Poll searches the unread documents of the inbox once, calls the handler for each change
+// since the previous poll, then saves the cursor.
+// If the cursor cannot be saved, the same changes are reported again by the next poll.
+// So are they if the context is done while they are handled, such as by a handler stopping the watch:
+// the remaining changes are not handled and the cursor is not saved.
+func (w *Watcher) Poll(ctx context.Context, handle func(Event)) error {
+	// The cursor is only kept once a poll succeeded, so that a failed first poll is still the first one.
+	cursor, first := w.cursor, false
+
+	if cursor == nil {
+		loaded, found, err := w.loadCursor()
+		if err != nil {
+			return err
+		}
+
+		cursor, first = loaded, !found
+	}
+
+	documents, err := w.client.SearchDocumentsIter(ctx, digiposte.RootFolderID,
+		digiposte.OnlyDocumentLocatedAt(digiposte.LocationInbox),
+		digiposte.UnreadDocuments(),
+		digiposte.IncludeSubFolders(),
+	).All()
+	if err != nil {
+		return fmt.Errorf("search inbox: %w", err)
+	}
+
+	next := newCursor()
+	next.PolledAt = time.Now().UTC()
+
+	for _, document := range documents {
+		next.Documents[document.InternalID] = stateOf(document)
+	}
+
+	if !first || !w.ignoreExisting {
+		for _, event := range cursor.diff(documents) {
+			if ctx.Err() != nil {
+				break
+			}
+
+			handle(event)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if err := w.saveCursor(next); err != nil {
+		return err
+	}
+
+	w.cursor = next
+
+	return nil
+}
+
+// diff returns the events from the cursor to the documents: the changes in the order of the documents,
+// then the removed documents by ID.
+func (c *Cursor) diff(documents []*digiposte.Document) []Event {
+	var events []Event
+
+	seen := make(map[digiposte.DocumentID]bool, len(documents))
+
+	for _, document := range documents {
+		seen[document.InternalID] = true
+
+		previous, ok := c.Documents[document.InternalID]
+		if !ok {
+			events = append(events, Event{
+				Kind:       DocumentAdded,
+				DocumentID: document.InternalID,
+				Document:   document,
+				Previous:   nil,
+			})
+
+			continue
+		}
+
+		current := stateOf(document)
+
+		if previous.Name != current.Name || previous.FolderID != current.FolderID {
+			events = append(events, Event{
+				Kind:       DocumentMoved,
+				DocumentID: document.InternalID,
+				Document:   document,
+				Previous:   &previous,
+			})
+		}
+
+		if !utils.SameElements(previous.Tags, current.Tags) {
+			events = append(events, Event{
+				Kind:       TagsChanged,
+				DocumentID: document.InternalID,
+				Document:   document,
+				Previous:   &previous,
+			})
+		}
+	}
+
+	removed := make([]digiposte.DocumentID, 0, len(c.Documents))
+
+	for id := range c.Documents {
+		if !seen[id] {
+			removed = append(removed, id)
+		}
+	}
+
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i] < removed[j]
+	})
+
+	for _, id := range removed {
+		previous := c.Documents[id]
+
+		events = append(events, Event{
+			Kind:       DocumentRemoved,
+			DocumentID: id,
+			Document:   nil,
+			Previous:   &previous,
+		})
+	}
+
+	return events
+}
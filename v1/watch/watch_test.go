@@ -0,0 +1,141 @@
+package watch_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+
+	"github.com/holyhope/digiposte-go-sdk/v1"
+	"github.com/holyhope/digiposte-go-sdk/v1/digipostetest"
+	"github.com/holyhope/digiposte-go-sdk/v1/watch"
+)
+
+var _ = ginkgo.Describe("Watcher", func() {
+	var (
+		server *digipostetest.Server
+		client *digiposte.Client
+		bill   *digiposte.Document
+	)
+
+	ginkgo.BeforeEach(func(ctx ginkgo.SpecContext) {
+		server = digipostetest.NewServer()
+		ginkgo.DeferCleanup(server.Close)
+
+		var err error
+
+		client, err = digiposte.NewAuthenticatedClient(ctx, new(http.Client), server.Config())
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		bill = server.AddDocument(digiposte.RootFolderID, "bill.pdf", []byte("bill"), digiposte.LocationInbox)
+		server.AddDocument(digiposte.RootFolderID, "safe.pdf", []byte("safe"), digiposte.LocationSafe)
+	})
+
+	poll := func(ctx context.Context, watcher *watch.Watcher) []watch.Event {
+		var events []watch.Event
+
+		gomega.Expect(watcher.Poll(ctx, func(event watch.Event) {
+			events = append(events, event)
+		})).To(gomega.Succeed())
+
+		return events
+	}
+
+	kinds := func(events []watch.Event) map[digiposte.DocumentID][]watch.EventKind {
+		result := make(map[digiposte.DocumentID][]watch.EventKind)
+
+		for _, event := range events {
+			result[event.DocumentID] = append(result[event.DocumentID], event.Kind)
+		}
+
+		return result
+	}
+
+	ginkgo.It("Should report the changes of the unread inbox", func(ctx ginkgo.SpecContext) {
+		watcher := watch.New(client)
+
+		events := poll(ctx, watcher)
+		gomega.Expect(events).To(gomega.HaveLen(1))
+		gomega.Expect(events[0].Kind).To(gomega.Equal(watch.DocumentAdded))
+		gomega.Expect(events[0].Document.Name).To(gomega.Equal("bill.pdf"))
+
+		gomega.Expect(poll(ctx, watcher)).To(gomega.BeEmpty())
+
+		payslip := server.AddDocument(digiposte.RootFolderID, "payslip.pdf", []byte("payslip"), digiposte.LocationInbox)
+		folder := server.AddFolder(digiposte.RootFolderID, "Bills")
+
+		gomega.Expect(client.Move(ctx, folder.InternalID, []digiposte.DocumentID{bill.InternalID}, nil)).
+			To(gomega.Succeed())
+		gomega.Expect(client.MultiTag(ctx, map[digiposte.DocumentID][]digiposte.DocumentTag{
+			bill.InternalID: {"energy"},
+		})).To(gomega.Succeed())
+
+		gomega.Expect(kinds(poll(ctx, watcher))).To(gomega.Equal(map[digiposte.DocumentID][]watch.EventKind{
+			bill.InternalID:    {watch.DocumentMoved, watch.TagsChanged},
+			payslip.InternalID: {watch.DocumentAdded},
+		}))
+
+		gomega.Expect(client.MarkRead(ctx, []digiposte.DocumentID{payslip.InternalID})).To(gomega.Succeed())
+
+		events = poll(ctx, watcher)
+		gomega.Expect(kinds(events)).To(gomega.Equal(map[digiposte.DocumentID][]watch.EventKind{
+			payslip.InternalID: {watch.DocumentRemoved},
+		}))
+		gomega.Expect(events[0].Previous.Name).To(gomega.Equal("payslip.pdf"))
+	})
+
+	ginkgo.It("Should resume from the cursor file", func(ctx ginkgo.SpecContext) {
+		cursor := filepath.Join(ginkgo.GinkgoT().TempDir(), "cursor.json")
+
+		gomega.Expect(poll(ctx, watch.New(client, watch.WithCursorFile(cursor), watch.IgnoreExisting()))).
+			To(gomega.BeEmpty())
+		gomega.Expect(cursor).To(gomega.BeAnExistingFile())
+
+		payslip := server.AddDocument(digiposte.RootFolderID, "payslip.pdf", []byte("payslip"), digiposte.LocationInbox)
+
+		gomega.Expect(kinds(poll(ctx, watch.New(client, watch.WithCursorFile(cursor), watch.IgnoreExisting())))).
+			To(gomega.Equal(map[digiposte.DocumentID][]watch.EventKind{
+				payslip.InternalID: {watch.DocumentAdded},
+			}))
+	})
+
+	ginkgo.It("Should ignore the existing documents after a failed first poll", func(ctx ginkgo.SpecContext) {
+		watcher := watch.New(client, watch.IgnoreExisting())
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		gomega.Expect(watcher.Poll(canceled, func(watch.Event) {})).ToNot(gomega.Succeed())
+		gomega.Expect(poll(ctx, watcher)).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should reject a non-positive interval", func(ctx ginkgo.SpecContext) {
+		err := watch.New(client, watch.WithInterval(0)).Run(ctx, func(watch.Event) {})
+		gomega.Expect(err).To(gomega.MatchError(digiposte.ErrValidation))
+	})
+
+	ginkgo.It("Should back off on errors until the context is done", func(ctx ginkgo.SpecContext) {
+		server.Close()
+
+		var errs []error
+
+		watcher := watch.New(client,
+			watch.WithInterval(time.Millisecond),
+			watch.WithMaxBackoff(4*time.Millisecond),
+			watch.WithErrorHandler(func(err error) {
+				errs = append(errs, err)
+			}),
+		)
+
+		runCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		err := watcher.Run(runCtx, func(watch.Event) {})
+		gomega.Expect(errors.Is(err, context.DeadlineExceeded)).To(gomega.BeTrue())
+		gomega.Expect(errs).ToNot(gomega.BeEmpty())
+	})
+})